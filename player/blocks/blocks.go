@@ -1,14 +1,23 @@
 package blocks
 
 import (
+	"fmt"
+	"strings"
 	"tale/tokens"
 )
 
+// Line and Column are the position of the header, or the first token of a
+// start block. It is the object "it" refers to in the body and in the
+// headers of child blocks, see ResolveIt.
 type Block struct {
+	Path string
+	Line int
+	Column int
 	Type BlockType
 	Header []Expression
 	Body []BodyNode
 	ChildBlocks []Block
+	It string
 }
 
 type BlockType uint8
@@ -38,6 +47,103 @@ type Expression struct {
 	Right *Expression
 }
 
+// Formats expression trees as nested prefix lists, e.g. "(and (is room dark) lit)"
+func (e Expression) String() string {
+	literal := e.Token.Literal
+	if e.Token.Type == tokens.TEXT {
+		literal = fmt.Sprintf("%q", literal)
+	}
+
+	switch {
+	case e.Left != nil && e.Right != nil:
+		return fmt.Sprintf("(%s %s %s)", literal, e.Left, e.Right)
+	case e.Right != nil:
+		return fmt.Sprintf("(%s %s)", literal, e.Right)
+	default:
+		return literal
+	}
+}
+
+type BodyNodeType uint8
+
+const (
+	INVALID_NODE BodyNodeType = iota
+	TEXT_NODE
+	ACTION_NODE
+	INTERPOLATION_NODE
+	ENCLOSING_NODE
+)
+
+func (nt BodyNodeType) String() string {
+	switch nt {
+	case INVALID_NODE: return "Invalid Node"
+	case TEXT_NODE: return "Text Node"
+	case ACTION_NODE: return "Action Node"
+	case INTERPOLATION_NODE: return "Interpolation Node"
+	case ENCLOSING_NODE: return "Enclosing Node"
+	default: return "Invalid Node Value!"
+	}
+}
+
+// Text nodes only use Text. Actions have a Name and Args, interpolations
+// only Args, and enclosing actions a Name, Args, and Children.
 type BodyNode struct {
+	Type BodyNodeType
+	Token tokens.Token
 	Text string
+	Name string
+	Args []Expression
+	Children []BodyNode
+}
+
+func (n BodyNode) String() string {
+	var parts []string
+	if n.Name != "" {
+		parts = append(parts, n.Name)
+	}
+	for _, arg := range n.Args {
+		parts = append(parts, arg.String())
+	}
+	action := "{" + strings.Join(parts, " ") + "}"
+
+	switch n.Type {
+	case TEXT_NODE:
+		return fmt.Sprintf("%q", n.Text)
+	case ACTION_NODE, INTERPOLATION_NODE:
+		return action
+	case ENCLOSING_NODE:
+		var children []string
+		for _, child := range n.Children {
+			children = append(children, child.String())
+		}
+		return action + strings.Join(children, "") + "{/" + n.Name + "}"
+	default:
+		return "{?}"
+	}
+}
+
+// A name on its own, like "door", rather than part of a larger expression
+func IsBareName(expr Expression) bool {
+	return expr.Token.Type == tokens.NAME && expr.Left == nil && expr.Right == nil
+}
+
+// The actions built into Tale Maker, as listed in the overview
+func IsAction(name string) bool {
+	switch name {
+	case "alias", "b", "chain", "chance", "choice", "choose", "do", "i",
+		"if", "name", "place", "set", "title", "unset":
+		return true
+	default:
+		return false
+	}
+}
+
+// Built-in actions which must always enclose display text
+func IsEnclosingAction(name string) bool {
+	switch name {
+	case "b", "chain", "chance", "choice", "choose", "i", "if", "title":
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,51 @@
+package blocks
+
+import (
+	"fmt"
+	"tale/tokens"
+)
+
+func isOperand(e Expression) bool {
+	return (e.Left == nil && e.Right == nil) ||
+		e.Token.Type == tokens.OF ||
+		e.Token.Type == tokens.COLON ||
+		(e.Left == nil && e.Token.Type == tokens.MINUS)
+}
+
+func formatOperand(e *Expression) string {
+	if isOperand(*e) {
+		return e.Format()
+	}
+	return "(" + e.Format() + ")"
+}
+
+// Formats the expression as it would be written in a tale, e.g.
+// "door is not locked" or "(score + 1) * 2"
+func (e Expression) Format() string {
+	switch {
+	case e.Left == nil && e.Right == nil:
+		if e.Token.Type == tokens.TEXT {
+			return fmt.Sprintf("%q", e.Token.Literal)
+		}
+		return e.Token.Literal
+
+	case e.Token.Type == tokens.NOT && e.Right.Token.Type == tokens.IS:
+		return fmt.Sprintf("%s is not %s", formatOperand(e.Right.Left), formatOperand(e.Right.Right))
+
+	case e.Token.Type == tokens.NOT && e.Right.Left != nil &&
+		(e.Right.Token.Type == tokens.HAS || e.Right.Token.Type == tokens.IN || e.Right.Token.Type == tokens.WITH):
+		return fmt.Sprintf("%s not %s %s", formatOperand(e.Right.Left), e.Right.Token.Literal, formatOperand(e.Right.Right))
+
+	case e.Token.Type == tokens.COLON:
+		return formatOperand(e.Left) + ":" + formatOperand(e.Right)
+
+	case e.Left == nil && e.Token.Type == tokens.MINUS:
+		return "-" + formatOperand(e.Right)
+
+	case e.Left == nil:
+		return e.Token.Literal + " " + formatOperand(e.Right)
+
+	default:
+		return fmt.Sprintf("%s %s %s", formatOperand(e.Left), e.Token.Literal, formatOperand(e.Right))
+	}
+}
@@ -0,0 +1,81 @@
+package blocks
+
+import (
+	"slices"
+	"tale/tokens"
+)
+
+// The object every game has for its main character, which "it" never
+// refers to
+const PLAYER = "player"
+
+type itFinder struct {
+	objects map[string]bool
+	parent string
+	candidates []string
+}
+
+func (f *itFinder) add(object string) {
+	if object != "" && object != PLAYER && !slices.Contains(f.candidates, object) {
+		f.candidates = append(f.candidates, object)
+	}
+}
+
+// The names of values, like "locked" in "locked of door", are skipped
+func (f *itFinder) findInExpression(e *Expression) {
+	if e == nil {
+		return
+	}
+
+	switch e.Token.Type {
+	case tokens.IT:
+		f.add(f.parent)
+	case tokens.NAME:
+		if e != nil && IsBareName(*e) && f.objects[e.Token.Literal] {
+			f.add(e.Token.Literal)
+		}
+	case tokens.OF:
+		f.findInExpression(e.Right)
+	case tokens.COLON:
+		f.findInExpression(e.Left)
+	default:
+		f.findInExpression(e.Left)
+		f.findInExpression(e.Right)
+	}
+}
+
+// The objects other than the player named in a header, in the order they
+// appear, which "it" could refer to within the block. An "it" in the header
+// itself stands for parent, the object of the wrapping block.
+func ItCandidates(header []Expression, objects map[string]bool, parent string) []string {
+	f := &itFinder{objects: objects, parent: parent}
+	for i := range header {
+		f.findInExpression(&header[i])
+	}
+	return f.candidates
+}
+
+func resolveIt(taleBlocks []Block, objects map[string]bool, parent string) {
+	for i := range taleBlocks {
+		block := &taleBlocks[i]
+
+		block.It = ""
+		if candidates := ItCandidates(block.Header, objects, parent); len(candidates) == 1 {
+			block.It = candidates[0]
+		}
+
+		resolveIt(block.ChildBlocks, objects, block.It)
+	}
+}
+
+// Sets It in place for every block to the one object its header names.
+// Blocks whose header names no object or several keep an empty It, which
+// the check package reports wherever "it" is used.
+func ResolveIt(taleBlocks []Block) {
+	objects := make(map[string]bool)
+	for _, object := range FindObjects(taleBlocks) {
+		objects[object] = true
+	}
+
+	resolveIt(taleBlocks, objects, "")
+}
@@ -0,0 +1,117 @@
+package blocks
+
+import (
+	"slices"
+	"tale/tokens"
+)
+
+type objectFinder struct {
+	objects map[string]bool
+	values map[string]bool
+	flagChecks []Expression
+}
+
+func (f *objectFinder) addName(e *Expression) {
+	if e != nil && IsBareName(*e) {
+		f.objects[e.Token.Literal] = true
+	}
+}
+
+// A variable given a value of its own, like "score" in {set score 3}
+func (f *objectFinder) addValue(e *Expression) {
+	if e != nil && IsBareName(*e) {
+		f.values[e.Token.Literal] = true
+	}
+}
+
+func (f *objectFinder) findInExpression(e *Expression) {
+	if e == nil {
+		return
+	}
+
+	switch e.Token.Type {
+	case tokens.OF:
+		f.addName(e.Right)
+	case tokens.COLON:
+		f.addName(e.Left)
+	case tokens.IN, tokens.HAS, tokens.WITH:
+		f.addName(e.Left)
+		f.addName(e.Right)
+	case tokens.IS:
+		if e.Left != nil && IsBareName(*e.Left) && IsBareName(*e.Right) {
+			f.flagChecks = append(f.flagChecks, *e)
+		}
+	}
+
+	f.findInExpression(e.Left)
+	f.findInExpression(e.Right)
+}
+
+func (f *objectFinder) findInBody(body []BodyNode) {
+	for _, node := range body {
+		switch node.Name {
+		case "place":
+			for i := range node.Args {
+				f.addName(&node.Args[i])
+			}
+		case "name":
+			if len(node.Args) > 0 {
+				f.addName(&node.Args[0])
+			}
+		case "set":
+			f.findSetValue(node)
+		}
+
+		for i := range node.Args {
+			f.findInExpression(&node.Args[i])
+		}
+		f.findInBody(node.Children)
+	}
+}
+
+func (f *objectFinder) findSetValue(node BodyNode) {
+	args := node.Args
+	switch {
+	case len(args) == 2, len(args) == 1 && node.Type == ENCLOSING_NODE:
+		f.addValue(&args[0])
+	case len(args) == 1 && IsBareName(args[0]):
+		f.addValue(&args[0])
+	case len(args) == 1 && args[0].Token.Type == tokens.IS && !IsBareName(*args[0].Right):
+		f.addValue(args[0].Left)
+	}
+}
+
+func (f *objectFinder) findInBlocks(taleBlocks []Block) {
+	for _, block := range taleBlocks {
+		for i := range block.Header {
+			f.findInExpression(&block.Header[i])
+		}
+		f.findInBody(block.Body)
+		f.findInBlocks(block.ChildBlocks)
+	}
+}
+
+// Names used as objects anywhere in the blocks: located with "in", "has",
+// or "with", accessed with "of" or ":", or used with "place" or "name".
+// The left side of "door is locked" is an object too, unless either side
+// is set as a value, like "score is goal", or the right side is an object,
+// in which case the two are being compared.
+func FindObjects(taleBlocks []Block) []string {
+	f := &objectFinder{objects: make(map[string]bool), values: make(map[string]bool)}
+	f.findInBlocks(taleBlocks)
+
+	for _, check := range f.flagChecks {
+		left, right := check.Left.Token.Literal, check.Right.Token.Literal
+		if !f.values[left] && !f.values[right] && !f.objects[right] {
+			f.objects[left] = true
+		}
+	}
+
+	var names []string
+	for name := range f.objects {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
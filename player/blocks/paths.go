@@ -0,0 +1,37 @@
+package blocks
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// The directory containing every file of a tale, so files can be named
+// the same way wherever the tale is moved to
+func CommonDir(taleBlocks []Block) string {
+	if len(taleBlocks) == 0 {
+		return ""
+	}
+
+	root := filepath.Dir(taleBlocks[0].Path)
+	for _, block := range taleBlocks[1:] {
+		dir := filepath.Dir(block.Path)
+		for !isWithin(dir, root) && filepath.Dir(root) != root {
+			root = filepath.Dir(root)
+		}
+	}
+	return root
+}
+
+func isWithin(dir string, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".." + string(filepath.Separator))
+}
+
+// Names a file relative to the root of its tale using forward slashes,
+// such as "rooms/cell.tale"
+func RelativePath(root string, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"tale/check"
+	"tale/diagnostics"
+	"tale/loader"
+)
+
+// Prints a diagnostic with its path relative to the working directory
+func printDiagnostic(pwd string, diag diagnostics.Diagnostic) {
+	if relPath, err := filepath.Rel(pwd, diag.Path); err == nil {
+		diag.Path = relPath
+	}
+	fmt.Fprintln(os.Stderr, diag)
+}
+
+func checkTale(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tale check [directory or .tale file]...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files := loader.Load(findTalePaths(flags.Args()))
+	diags := loader.Errors(files)
+
+	// Analysis of a tale which failed to parse would mostly report noise
+	if len(diags) == 0 {
+		diags = check.All(loader.Blocks(files))
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, diag := range diags {
+		printDiagnostic(pwd, diag)
+
+		if diag.IsError() {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d files checked, %d errors, %d warnings\n", len(files), errorCount, warningCount)
+
+	if errorCount > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,75 @@
+package check
+
+import (
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/tokens"
+)
+
+type actionChecker struct {
+	path string
+	diagnostics []diagnostics.Diagnostic
+}
+
+func (c *actionChecker) errorf(token tokens.Token, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, token, format, args...))
+}
+
+// Enclosed text counts as a final input, so {name door}Door{/name} has two
+func countArgs(node blocks.BodyNode) int {
+	if node.Type == blocks.ENCLOSING_NODE {
+		return len(node.Args) + 1
+	}
+	return len(node.Args)
+}
+
+func (c *actionChecker) checkBody(body []blocks.BodyNode) {
+	for _, node := range body {
+		switch node.Name {
+		case "unset":
+			if len(node.Args) == 0 {
+				c.errorf(node.Token, "unset expects a variable")
+			}
+		case "place":
+			if len(node.Args) != 2 {
+				c.errorf(node.Token, "place expects an object and a location")
+			}
+		case "name":
+			if countArgs(node) != 2 {
+				c.errorf(node.Token, "name expects an object and its name")
+			}
+		case "alias":
+			if len(node.Args) == 0 || node.Args[0].Token.Type != tokens.NAME {
+				c.errorf(node.Token, "alias expects the name of an alias followed by its inputs")
+			}
+		case "do":
+			for _, arg := range node.Args {
+				if arg.Token.Type != tokens.NAME {
+					c.errorf(arg.Token, "do expects the names of aliases")
+				}
+			}
+		case "if":
+			if len(node.Args) != 1 {
+				c.errorf(node.Token, "if expects a single condition")
+			}
+		}
+
+		c.checkBody(node.Children)
+	}
+}
+
+func (c *actionChecker) checkBlocks(taleBlocks []blocks.Block) {
+	for _, block := range taleBlocks {
+		c.path = block.Path
+		c.checkBody(block.Body)
+		c.checkBlocks(block.ChildBlocks)
+	}
+}
+
+// Finds actions given the wrong number or kind of inputs, which the game
+// would otherwise only report when they run
+func Actions(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &actionChecker{}
+	c.checkBlocks(taleBlocks)
+	return c.diagnostics
+}
@@ -0,0 +1,62 @@
+package check
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/state"
+	"tale/tokens"
+)
+
+// The leftmost token of an expression, where a writer would look for it
+func firstToken(expr blocks.Expression) tokens.Token {
+	for expr.Left != nil {
+		expr = *expr.Left
+	}
+	return expr.Token
+}
+
+// Points a message at another part of the tale, such as "rooms/cell.tale:12:1",
+// naming the file relative to the tale's root like the game does
+func position(root string, path string, token tokens.Token) string {
+	return fmt.Sprintf("%s:%d:%d", blocks.RelativePath(root, path), token.Line, token.Column)
+}
+
+// Every object in a tale, including the built-in player and tale
+func findObjects(taleBlocks []blocks.Block) map[string]bool {
+	objects := map[string]bool{
+		state.PLAYER: true,
+		state.TALE: true,
+	}
+
+	for _, object := range blocks.FindObjects(taleBlocks) {
+		objects[object] = true
+	}
+
+	return objects
+}
+
+// Runs every analysis over the blocks of a tale, returning diagnostics
+// sorted by their position
+func All(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	var diags []diagnostics.Diagnostic
+	diags = append(diags, Names(taleBlocks)...)
+	diags = append(diags, It(taleBlocks)...)
+	diags = append(diags, Choices(taleBlocks)...)
+	diags = append(diags, Actions(taleBlocks)...)
+	diags = append(diags, Types(taleBlocks)...)
+	diags = append(diags, Starts(taleBlocks)...)
+	diags = append(diags, Reachability(taleBlocks)...)
+
+	slices.SortStableFunc(diags, func(a, b diagnostics.Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+
+	return diags
+}
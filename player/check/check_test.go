@@ -0,0 +1,346 @@
+package check
+
+import (
+	"strings"
+	"tale/diagnostics"
+	"tale/parser"
+	"testing"
+)
+
+func expectDiagnostics(t *testing.T, dir string, diags []diagnostics.Diagnostic, expected []string) {
+	t.Helper()
+
+	if len(diags) != len(expected) {
+		t.Fatalf("wrong number of diagnostics, expected=%d, got=%d: %v", len(expected), len(diags), diags)
+	}
+
+	for i, diag := range diags {
+		got := strings.TrimPrefix(diag.Error(), dir + "/")
+		if got != expected[i] {
+			t.Fatalf("wrong diagnostic, expected=%q, got=%q", expected[i], got)
+		}
+	}
+}
+
+func TestTypes(t *testing.T) {
+	tests := []struct {
+		sources []string
+		expected []string
+	}{
+		{
+			[]string{"{set score 0}\n> score >\nScore: {score}\n{set score score + 1}"},
+			[]string{},
+		},
+		{
+			[]string{"{set score 0}\n{set total score}\n> total >\n{total}"},
+			[]string{},
+		},
+		{
+			[]string{"{set lit}\n== lit ==\nYou see.", "> light >\n{unset lit}"},
+			[]string{},
+		},
+		{
+			[]string{"{set score 0}\n> score >\n{score}", "> name >\n{set score \"high\"}"},
+			[]string{"2.tale:2:6: error: score is a number (set at 1.tale:1:6), but is set to a text here, a variable's type may not change"},
+		},
+		{
+			[]string{"{set total score}\n{total}\n> score >\n{set score \"high\"}\n{set total 3}"},
+			[]string{"1.tale:5:6: error: total is a text (set at 1.tale:1:6), but is set to a number here, a variable's type may not change"},
+		},
+		{
+			[]string{"{set a x c}\n{set}\n{set score 1}{set score is not 2}\n{place key box}{set key not in box}\n{score}"},
+			[]string{
+				"1.tale:1:1: error: set expects a variable and optionally a value",
+				"1.tale:2:1: error: set expects a variable and optionally a value",
+				"1.tale:3:25: error: cannot set score to not a value",
+				"1.tale:4:29: error: cannot set an object to not be in another, place it somewhere instead",
+			},
+		},
+		{
+			[]string{"{set greeting \"hi\"}\n{greeting + 1}"},
+			[]string{"1.tale:2:11: error: + only works with numbers, but greeting is a text"},
+		},
+		{
+			[]string{"{set greeting \"hi\"}\n== greeting is 3 ==\nHi."},
+			[]string{"1.tale:2:13: error: greeting is a text and 3 is a number, so they can never be the same"},
+		},
+		{
+			[]string{"== lit ==\nYou see.\n{set dark}"},
+			[]string{
+				"1.tale:1:4: error: lit is used but never set, set it with {set lit}",
+				"1.tale:3:6: error: dark is set but never used",
+			},
+		},
+		{
+			[]string{"{place lamp player}\n{set lamp is lit}\n{set description of lamp \"A lamp\"}\n== lamp is lit ==\n{name of lamp}"},
+			[]string{},
+		},
+		{
+			[]string{"{place lamp player}\n== lamp is lit ==\n{set color of lamp 3}\n{set name of lamp 3}"},
+			[]string{
+				"1.tale:4:6: error: name of lamp must always be a text, but is set to a number here",
+				"1.tale:2:12: error: lit of lamp is used but never set, set it with {set lit of lamp}",
+			},
+		},
+		{
+			[]string{"== repeat ==\nAgain."},
+			[]string{},
+		},
+		{
+			[]string{"{set score 1}{set goal 1}\n== score is goal ==\nSame."},
+			[]string{},
+		},
+		{
+			[]string{"> key >\n\n== door is locked ==\n{set door is not locked}\nYou unlocked it!\n\n== door is not locked ==\n{set door is locked}\nYou locked it!"},
+			[]string{},
+		},
+		{
+			[]string{"> look >\n== repeats > 1 ==\nAgain and again.\n== repeats is \"often\" ==\nNever."},
+			[]string{"1.tale:4:12: error: repeats is a number and \"often\" is a text, so they can never be the same"},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, Types(parser.MustParseFiles(t, dir, tt.sources...)), tt.expected)
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		source string
+		expected []string
+	}{
+		{
+			"{set score 0}\n> score >\n{score}\n{place lamp player}\n{set lamp is lit}\n== lamp is lit ==\n{name of lamp}",
+			[]string{},
+		},
+		{
+			"{set set 3}\n== place ==\nHi.",
+			[]string{
+				"1.tale:1:6: error: \"set\" is the name of an action, so it can't also be used as a variable or object, try a different name",
+				"1.tale:2:4: error: \"place\" is the name of an action, so it can't also be used as a variable or object, try a different name",
+			},
+		},
+		{
+			"== any ==\nHi.\n{alias any \"hi\"}\n> any >\nHuh?",
+			[]string{
+				"1.tale:1:4: error: any is a special alias which only works in an input header like \"> any >\", it can't be used as a variable or object",
+				"1.tale:3:8: error: any already matches every input, so it can't be given aliases",
+			},
+		},
+		{
+			"{set repeat}\n{unset repeat}\n== repeat ==\nAgain.",
+			[]string{
+				"1.tale:1:6: error: repeat is set automatically when a block repeats, so it can't be used with {set}",
+				"1.tale:2:8: error: repeat is set automatically when a block repeats, so it can't be used with {unset}",
+			},
+		},
+		{
+			"{name of repeat}\n{place lamp repeat}",
+			[]string{
+				"1.tale:1:10: error: repeat is a special flag which is set when a block repeats, it can't be used as an object",
+				"1.tale:2:13: error: repeat is a special flag which is set when a block repeats, it can't be used as an object",
+			},
+		},
+		{
+			"{set score 1}{set goal 1}\n== score is goal ==\nSame.",
+			[]string{},
+		},
+		{
+			"> key >\n\n== door is locked ==\n{set door is not locked}\nYou unlocked it!\n\n== door is not locked ==\n{set door is locked}\nYou locked it!",
+			[]string{},
+		},
+		{
+			"{set 3d 1}\n{place lamp 2nd_room}\n{set score 3 d}",
+			[]string{
+				"1.tale:1:6: error: \"3d\" can't be used as a name because it starts with a digit, names must start with a letter or underscore",
+				"1.tale:2:13: error: \"2nd_room\" can't be used as a name because it starts with a digit, names must start with a letter or underscore",
+			},
+		},
+		{
+			"{set repeats 2}\n{place repeats cell}",
+			[]string{
+				"1.tale:1:6: error: repeats is set automatically when a block repeats, so it can't be used with {set}",
+				"1.tale:2:8: error: repeats is a special number which is set when a block repeats, it can't be used as an object",
+			},
+		},
+		{
+			"{set player 3}\n{place lamp player}\n{set lamp}",
+			[]string{
+				"1.tale:1:6: error: player is a special object, so it can't hold a value itself, {set} one of its values instead, like {set score of player}",
+				"1.tale:3:6: error: lamp is an object, so it can't hold a value itself, {set} one of its values instead, like {set score of lamp}",
+			},
+		},
+		{
+			"{place player cell}\n= cell =\nA cell.\n= player or not cell =\nNever.",
+			[]string{
+				"1.tale:4:3: error: player on its own means the player is inside the player, which can never be true, check a value instead, like \"player is ready\"",
+			},
+		},
+		{
+			"{set café…_open}\n== 世界 ==\n{世界}\n{set 😀}",
+			[]string{
+				"1.tale:1:6: error: \"café…_open\" can't be used as a name because it includes '…', names may only use letters, numbers, and underscores",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, Names(parser.MustParseFiles(t, dir, tt.source)), tt.expected)
+	}
+}
+
+func TestIt(t *testing.T) {
+	tests := []struct {
+		source string
+		expected []string
+	}{
+		{
+			"{place player cell}\n> leave >\n== player in cell ==\nThe door of {it}.\n=== it is open ===\n{set it is not open}{name of it}\n> open >\n>> door >>\n{set door is open}{set it is open}",
+			[]string{},
+		},
+		{
+			"{it}\n> look >\n{name of it}\n== player in cell and lamp in cell ==\n{it}\n=== it is lit ===\nLit.",
+			[]string{
+				"1.tale:1:2: error: it refers to the object in the header wrapping it, but there is no object other than player there, name the object instead",
+				"1.tale:3:10: error: it refers to the object in the header wrapping it, but there is no object other than player there, name the object instead",
+				"1.tale:5:2: error: it could refer to cell or lamp, the header wrapping it must have exactly one object other than player, name the object instead",
+				"1.tale:6:5: error: it could refer to cell or lamp, the header wrapping it must have exactly one object other than player, name the object instead",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, It(parser.MustParseFiles(t, dir, tt.source)), tt.expected)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	tests := []struct {
+		source string
+		expected []string
+	}{
+		{
+			"{chain}\n{choice player is strong}Hey, big fella{/choice}\n{choice}Hey{/choice}\n{/chain}",
+			[]string{},
+		},
+		{
+			"{choose}Hi {choice}Hey{/choice}{set greeted}{/choose}\n{chance}{/chance}\n{choice}Howdy{/choice}",
+			[]string{
+				"1.tale:1:9: error: text directly inside {choose} is never displayed, wrap it in {choice}...{/choice}",
+				"1.tale:1:32: error: only choices may be directly inside {choose}, move this into a {choice}",
+				"1.tale:2:1: error: {chance} has no choices to display, add some with {choice}...{/choice}",
+				"1.tale:3:1: error: choice only works directly inside {choose}, {chain}, or {chance}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, Choices(parser.MustParseFiles(t, dir, tt.source)), tt.expected)
+	}
+}
+
+func TestActions(t *testing.T) {
+	tests := []struct {
+		source string
+		expected []string
+	}{
+		{
+			"{place key box}{name key \"Key\"}{name box}Box{/name}\n{alias key}{do key}\n{if key in box}Found it.{/if}",
+			[]string{},
+		},
+		{
+			"{place entrance_door entrance parlor}\n{name handler}{name of handler}\n{alias}{alias \"key\"}\n{if}Never.{/if}{if key box}Never.{/if}\n{unset}{do \"key\"}",
+			[]string{
+				"1.tale:1:1: error: place expects an object and a location",
+				"1.tale:2:1: error: name expects an object and its name",
+				"1.tale:3:1: error: alias expects the name of an alias followed by its inputs",
+				"1.tale:3:8: error: alias expects the name of an alias followed by its inputs",
+				"1.tale:4:1: error: if expects a single condition",
+				"1.tale:4:16: error: if expects a single condition",
+				"1.tale:5:1: error: unset expects a variable",
+				"1.tale:5:12: error: do expects the names of aliases",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, Actions(parser.MustParseFiles(t, dir, tt.source)), tt.expected)
+	}
+}
+
+func TestStarts(t *testing.T) {
+	tests := []struct {
+		sources []string
+		expected []string
+	}{
+		{
+			[]string{"{set score 1}\n{set score 2}\n{place lamp player}", "{set score 2}\n{set door is locked}\n{if score is 2}{set score 5}{/if}", "{set score 2.0}{set lives 3}\n{set locked of door}"},
+			[]string{},
+		},
+		{
+			[]string{"{set score 1}\n{place lamp player}\n{set door is locked}\n{name lamp \"Lamp\"}", "{set score 3}\n{place lamp cellar}\n{set score 3}\n{set door is not locked}\n{name lamp \"Old Lamp\"}\n\n> look >\n{set score 4}"},
+			[]string{
+				"2.tale:1:6: warning: score is set to 3 here, but the start block at 1.tale:1:6 set it to 1, start blocks in different files should not disagree",
+				"2.tale:2:1: warning: location of lamp is set to cellar here, but the start block at 1.tale:2:1 set it to player, start blocks in different files should not disagree",
+				"2.tale:4:11: warning: locked of door is set to no here, but the start block at 1.tale:3:11 set it to yes, start blocks in different files should not disagree",
+				"2.tale:5:1: warning: name of lamp is set to Old Lamp here, but the start block at 1.tale:4:1 set it to Lamp, start blocks in different files should not disagree",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, Starts(parser.MustParseFiles(t, dir, tt.sources...)), tt.expected)
+	}
+}
+
+func TestReachability(t *testing.T) {
+	tests := []struct {
+		sources []string
+		expected []string
+	}{
+		{
+			[]string{"> greet >\nHello!\n>> wave >>\nHi!\n== polite ==\nGood day.\n\n> any >\nHuh?"},
+			[]string{},
+		},
+		{
+			[]string{"> greet >\nHello!", "> greet >\nHi!\n\n> wave greet >\nHowdy!"},
+			[]string{"2.tale:1:1: warning: this block duplicates the block at 1.tale:1:1, which always takes priority, so it will never be triggered"},
+		},
+		{
+			[]string{"> greet >\nHello!\n>> greet >>\nHi!"},
+			[]string{"1.tale:1:1: warning: this block will never be triggered, the block at 1.tale:3:1 matches every input it does and always takes priority"},
+		},
+		{
+			[]string{"= lit =\n>> look >>\nA room.\n= not lit =\nDark.\n>> look >>\n=== lit ===\nNever."},
+			[]string{"1.tale:7:1: warning: this block can never be triggered, \"lit\" contradicts \"not lit\" at 1.tale:4:1"},
+		},
+		{
+			[]string{"> look >\n== score is 1,000 and score is 1000.0 ==\nSame.\n== door is locked ==\n=== door is open ===\nFine.\n== score is 2 and score is 3 ==\nNever."},
+			[]string{"1.tale:7:1: warning: this block can never be triggered, \"score is 3\" contradicts \"score is 2\" at 1.tale:7:1"},
+		},
+		{
+			[]string{"> look >\n== ready is yes and ready is on ==\nReady.\n== score is 0.1 and score is 0.10000000000000001 ==\nNever."},
+			[]string{"1.tale:4:1: warning: this block can never be triggered, \"score is 0.10000000000000001\" contradicts \"score is 0.1\" at 1.tale:4:1"},
+		},
+		{
+			[]string{"> any >\nHuh?\n>> greet >>\nNever.\n>> wave >>\nYou wave.\n\n> greet >\nHello!"},
+			[]string{"1.tale:3:1: warning: this block can never be triggered, the \"any\" block at 1.tale:1:1 skips every input which matches the block at 1.tale:8:1"},
+		},
+		{
+			[]string{"> look >\nA room.\n== repeat ==\nStill a room.\n\n> search >\nNothing.\n== repeat ==\nStill nothing."},
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		expectDiagnostics(t, dir, Reachability(parser.MustParseFiles(t, dir, tt.sources...)), tt.expected)
+	}
+}
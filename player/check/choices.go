@@ -0,0 +1,68 @@
+package check
+
+import (
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+)
+
+func isChoosing(name string) bool {
+	return name == "choose" || name == "chain" || name == "chance"
+}
+
+type choiceChecker struct {
+	path string
+	diagnostics []diagnostics.Diagnostic
+}
+
+func (c *choiceChecker) checkChoosing(node blocks.BodyNode) {
+	choices := 0
+
+	for _, child := range node.Children {
+		switch {
+		case child.Type == blocks.ENCLOSING_NODE && child.Name == "choice":
+			choices++
+			if len(child.Args) > 1 {
+				c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, child.Token, "choice expects at most one condition, combine them with \"and\" or \"or\""))
+			}
+		case child.Type == blocks.TEXT_NODE:
+			if strings.TrimSpace(child.Text) != "" {
+				c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, child.Token, "text directly inside {%s} is never displayed, wrap it in {choice}...{/choice}", node.Name))
+			}
+		default:
+			c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, child.Token, "only choices may be directly inside {%s}, move this into a {choice}", node.Name))
+		}
+	}
+
+	if choices == 0 {
+		c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, node.Token, "{%s} has no choices to display, add some with {choice}...{/choice}", node.Name))
+	}
+}
+
+func (c *choiceChecker) checkBody(body []blocks.BodyNode, parent string) {
+	for _, node := range body {
+		if node.Name == "choice" && !isChoosing(parent) {
+			c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, node.Token, "choice only works directly inside {choose}, {chain}, or {chance}"))
+		}
+		if isChoosing(node.Name) {
+			c.checkChoosing(node)
+		}
+		c.checkBody(node.Children, node.Name)
+	}
+}
+
+func (c *choiceChecker) checkBlocks(taleBlocks []blocks.Block) {
+	for _, block := range taleBlocks {
+		c.path = block.Path
+		c.checkBody(block.Body, "")
+		c.checkBlocks(block.ChildBlocks)
+	}
+}
+
+// Finds choose, chain, and chance actions with anything but choices directly
+// inside, and choices outside of them
+func Choices(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &choiceChecker{}
+	c.checkBlocks(taleBlocks)
+	return c.diagnostics
+}
@@ -0,0 +1,82 @@
+package check
+
+import (
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/tokens"
+)
+
+type itChecker struct {
+	objects map[string]bool
+	path string
+	diagnostics []diagnostics.Diagnostic
+}
+
+func (c *itChecker) findInExpression(expr *blocks.Expression, found []tokens.Token) []tokens.Token {
+	if expr == nil {
+		return found
+	}
+	if expr.Token.Type == tokens.IT {
+		found = append(found, expr.Token)
+	}
+	found = c.findInExpression(expr.Left, found)
+	return c.findInExpression(expr.Right, found)
+}
+
+func (c *itChecker) findInBody(body []blocks.BodyNode, found []tokens.Token) []tokens.Token {
+	for _, node := range body {
+		for i := range node.Args {
+			found = c.findInExpression(&node.Args[i], found)
+		}
+		found = c.findInBody(node.Children, found)
+	}
+	return found
+}
+
+// Every use of "it" needs exactly one object to refer to
+func (c *itChecker) checkUses(uses []tokens.Token, candidates []string) {
+	if len(candidates) == 1 {
+		return
+	}
+
+	for _, token := range uses {
+		if len(candidates) == 0 {
+			c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, token, "it refers to the object in the header wrapping it, but there is no object other than player there, name the object instead"))
+		} else {
+			last := len(candidates) - 1
+			options := strings.Join(candidates[:last], ", ") + " or " + candidates[last]
+			c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, token, "it could refer to %s, the header wrapping it must have exactly one object other than player, name the object instead", options))
+		}
+	}
+}
+
+// Headers use "it" for the object of their parent's header, while bodies
+// use it for the object of their own header
+func (c *itChecker) checkBlocks(taleBlocks []blocks.Block, parentCandidates []string, parent string) {
+	for _, block := range taleBlocks {
+		c.path = block.Path
+
+		var headerUses []tokens.Token
+		for i := range block.Header {
+			headerUses = c.findInExpression(&block.Header[i], headerUses)
+		}
+		c.checkUses(headerUses, parentCandidates)
+
+		candidates := blocks.ItCandidates(block.Header, c.objects, parent)
+		c.checkUses(c.findInBody(block.Body, nil), candidates)
+
+		it := ""
+		if len(candidates) == 1 {
+			it = candidates[0]
+		}
+		c.checkBlocks(block.ChildBlocks, candidates, it)
+	}
+}
+
+// Finds uses of "it" which don't refer to exactly one object
+func It(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &itChecker{objects: findObjects(taleBlocks)}
+	c.checkBlocks(taleBlocks, nil, "")
+	return c.diagnostics
+}
@@ -0,0 +1,221 @@
+package check
+
+import (
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/state"
+	"tale/tokens"
+	"unicode"
+	"unicode/utf8"
+)
+
+const ANY = "any"
+
+// The lexer accepts most non-ASCII characters in names, so punctuation,
+// spaces, and math symbols from outside of ASCII are caught here instead
+func isReservedRune(r rune) bool {
+	return r > 127 && (unicode.IsSpace(r) ||
+		unicode.IsPunct(r) ||
+		unicode.IsControl(r) ||
+		unicode.Is(unicode.Sm, r))
+}
+
+type nameChecker struct {
+	objects map[string]bool
+	path string
+	diagnostics []diagnostics.Diagnostic
+}
+
+func (c *nameChecker) errorf(token tokens.Token, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, diagnostics.New(c.path, token, format, args...))
+}
+
+func (c *nameChecker) checkCharacters(token tokens.Token) {
+	if i := strings.IndexFunc(token.Literal, isReservedRune); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(token.Literal[i:])
+		c.errorf(token, "%q can't be used as a name because it includes %q, names may only use letters, numbers, and underscores", token.Literal, r)
+	}
+}
+
+// Names can't start with a digit, "3d" is read as the number 3 followed
+// by the name "d", so the two are found side by side
+func (c *nameChecker) checkDigitNames(args []blocks.Expression) {
+	for i := 1; i < len(args); i++ {
+		number, name := args[i - 1], args[i]
+		if number.Token.Type == tokens.NUMBER && number.Left == nil && number.Right == nil && blocks.IsBareName(name) &&
+			name.Token.Line == number.Token.Line && name.Token.Column == number.Token.Column + len(number.Token.Literal) {
+			c.errorf(number.Token, "%q can't be used as a name because it starts with a digit, names must start with a letter or underscore", number.Token.Literal + name.Token.Literal)
+		}
+	}
+}
+
+// A name used as a variable or object
+func (c *nameChecker) checkName(token tokens.Token) {
+	c.checkCharacters(token)
+	name := token.Literal
+
+	switch {
+	case blocks.IsAction(name):
+		c.errorf(token, "%q is the name of an action, so it can't also be used as a variable or object, try a different name", name)
+	case name == ANY:
+		c.errorf(token, "any is a special alias which only works in an input header like \"> any >\", it can't be used as a variable or object")
+	}
+}
+
+// A name used where only an object makes sense
+func (c *nameChecker) checkObject(expr *blocks.Expression) {
+	if expr != nil && blocks.IsBareName(*expr) && state.IsRepeat(expr.Token.Literal) {
+		c.errorf(expr.Token, "%s is a special %s which is set when a block repeats, it can't be used as an object", expr.Token.Literal, engineVariables[expr.Token.Literal])
+	}
+	c.checkExpression(expr)
+}
+
+// A value of an object, like "locked" in "door is locked"
+func (c *nameChecker) checkValueName(token tokens.Token) {
+	c.checkCharacters(token)
+}
+
+func (c *nameChecker) isObject(expr *blocks.Expression) bool {
+	return expr != nil && blocks.IsBareName(*expr) && c.objects[expr.Token.Literal]
+}
+
+func (c *nameChecker) checkExpression(expr *blocks.Expression) {
+	if expr == nil {
+		return
+	}
+
+	switch {
+	case blocks.IsBareName(*expr):
+		c.checkName(expr.Token)
+
+	case expr.Token.Type == tokens.OF:
+		if blocks.IsBareName(*expr.Left) {
+			c.checkValueName(expr.Left.Token)
+		} else {
+			c.checkExpression(expr.Left)
+		}
+		c.checkObject(expr.Right)
+
+	case expr.Token.Type == tokens.COLON:
+		c.checkObject(expr.Left)
+		if blocks.IsBareName(*expr.Right) {
+			c.checkValueName(expr.Right.Token)
+		} else {
+			c.checkExpression(expr.Right)
+		}
+
+	case expr.Token.Type == tokens.IS && c.isObject(expr.Left) && blocks.IsBareName(*expr.Right) && !c.objects[expr.Right.Token.Literal]:
+		c.checkExpression(expr.Left)
+		c.checkValueName(expr.Right.Token)
+
+	default:
+		c.checkExpression(expr.Left)
+		c.checkExpression(expr.Right)
+	}
+}
+
+// Set and unset may not target built-ins which hold no value of their own
+func (c *nameChecker) checkTarget(action string, target blocks.Expression) {
+	if !blocks.IsBareName(target) {
+		return
+	}
+
+	name := target.Token.Literal
+	switch {
+	case state.IsRepeat(name):
+		c.errorf(target.Token, "%s is set automatically when a block repeats, so it can't be used with {%s}", name, action)
+	case name == state.PLAYER || name == state.TALE:
+		c.errorf(target.Token, "%s is a special object, so it can't hold a value itself, {%s} one of its values instead, like {%s score of %s}", name, action, action, name)
+	case c.objects[name]:
+		c.errorf(target.Token, "%s is an object, so it can't hold a value itself, {%s} one of its values instead, like {%s score of %s}", name, action, action, name)
+	}
+}
+
+func (c *nameChecker) checkBody(body []blocks.BodyNode) {
+	for _, node := range body {
+		args := node.Args
+
+		switch node.Name {
+		case "set", "unset":
+			if len(args) > 0 {
+				c.checkTarget(node.Name, args[0])
+			}
+			c.checkDigitNames(args)
+			for i := range args {
+				c.checkExpression(&args[i])
+			}
+
+		case "alias":
+			if len(args) > 0 && blocks.IsBareName(args[0]) {
+				c.checkCharacters(args[0].Token)
+				if args[0].Token.Literal == ANY {
+					c.errorf(args[0].Token, "any already matches every input, so it can't be given aliases")
+				}
+				args = args[1:]
+			}
+			for i := range args {
+				c.checkExpression(&args[i])
+			}
+
+		case "place":
+			c.checkDigitNames(args)
+			for i := range args {
+				c.checkObject(&args[i])
+			}
+
+		case "do":
+			for _, arg := range args {
+				if blocks.IsBareName(arg) {
+					c.checkCharacters(arg.Token)
+				}
+			}
+
+		default:
+			for i := range args {
+				c.checkExpression(&args[i])
+			}
+		}
+
+		c.checkBody(node.Children)
+	}
+}
+
+// An object on its own in a header means the player is in it, which can
+// never be true of the player itself
+func (c *nameChecker) checkStateObjects(expr *blocks.Expression) {
+	switch {
+	case expr == nil:
+	case blocks.IsBareName(*expr) && expr.Token.Literal == state.PLAYER:
+		c.errorf(expr.Token, "player on its own means the player is inside the player, which can never be true, check a value instead, like \"player is ready\"")
+	case expr.Token.Type == tokens.AND, expr.Token.Type == tokens.OR, expr.Token.Type == tokens.NOT:
+		c.checkStateObjects(expr.Left)
+		c.checkStateObjects(expr.Right)
+	}
+}
+
+func (c *nameChecker) checkBlocks(taleBlocks []blocks.Block) {
+	for _, block := range taleBlocks {
+		c.path = block.Path
+
+		for i, expr := range block.Header {
+			if block.Type == blocks.STATE {
+				c.checkStateObjects(&block.Header[i])
+				c.checkExpression(&block.Header[i])
+			} else if blocks.IsBareName(expr) {
+				c.checkCharacters(expr.Token)
+			}
+		}
+
+		c.checkBody(block.Body)
+		c.checkBlocks(block.ChildBlocks)
+	}
+}
+
+// Checks that the names used in a tale are legal, and that built-in names
+// are only used in the ways the overview allows
+func Names(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &nameChecker{objects: findObjects(taleBlocks)}
+	c.checkBlocks(taleBlocks)
+	return c.diagnostics
+}
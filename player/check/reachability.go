@@ -0,0 +1,337 @@
+package check
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/state"
+	"tale/tokens"
+)
+
+// A condition which must hold for a block to be triggered, along with the
+// block whose header it came from
+type requirement struct {
+	expr blocks.Expression
+	owner *reachNode
+}
+
+// A block along with everything required to trigger it, which includes
+// the headers of every block wrapping it
+type reachNode struct {
+	block blocks.Block
+	parent *reachNode
+	order int
+	depth int
+	inputs int
+	matched bool
+	names map[string]bool
+	conditions map[string]requirement
+	anys []*reachNode
+	key string
+}
+
+func (n *reachNode) String() string {
+	return fmt.Sprintf("%s:%d:%d", n.block.Path, n.block.Line, n.block.Column)
+}
+
+func (n *reachNode) hasAncestor(ancestor *reachNode) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+type reachChecker struct {
+	root string
+	nodes []*reachNode
+	diagnostics []diagnostics.Diagnostic
+}
+
+func (c *reachChecker) position(node *reachNode) string {
+	return position(c.root, node.block.Path, tokens.Token{Line: node.block.Line, Column: node.block.Column})
+}
+
+func (c *reachChecker) warnf(node *reachNode, format string, args ...any) {
+	token := tokens.Token{Line: node.block.Line, Column: node.block.Column}
+	c.diagnostics = append(c.diagnostics, diagnostics.NewWarning(node.block.Path, token, format, args...))
+}
+
+// Splits a header condition on "and", since each part must hold separately
+func splitConditions(expr blocks.Expression, conditions []blocks.Expression) []blocks.Expression {
+	if expr.Token.Type == tokens.AND {
+		conditions = splitConditions(*expr.Left, conditions)
+		return splitConditions(*expr.Right, conditions)
+	}
+	return append(conditions, expr)
+}
+
+func usesName(expr *blocks.Expression, name string) bool {
+	if expr == nil {
+		return false
+	}
+	if blocks.IsBareName(*expr) && expr.Token.Literal == name {
+		return true
+	}
+	return expr.Token.Type == tokens.IT || usesName(expr.Left, name) || usesName(expr.Right, name)
+}
+
+// Conditions which depend on the block they are written in, like repeat,
+// are only the same condition when they come from the same block
+func conditionKey(expr blocks.Expression, owner *reachNode) string {
+	if usesName(&expr, state.REPEAT) || usesName(&expr, state.REPEATS) {
+		return owner.String() + " " + expr.String()
+	}
+	return expr.String()
+}
+
+func isLiteral(expr blocks.Expression) bool {
+	switch expr.Token.Type {
+	case tokens.NUMBER, tokens.TEXT, tokens.FLAG:
+		return expr.Left == nil && expr.Right == nil
+	default:
+		return false
+	}
+}
+
+func literalsEqual(a blocks.Expression, b blocks.Expression) bool {
+	if a.Token.Type != b.Token.Type {
+		return false
+	}
+
+	switch a.Token.Type {
+	case tokens.NUMBER:
+		aNum, aErr := state.ParseNum(a.Token.Literal)
+		bNum, bErr := state.ParseNum(b.Token.Literal)
+		return aErr == nil && bErr == nil && aNum.Cmp(bNum) == 0
+	case tokens.FLAG:
+		return state.ParseFlag(a.Token.Literal) == state.ParseFlag(b.Token.Literal)
+	default:
+		return a.Token.Literal == b.Token.Literal
+	}
+}
+
+// Whether two conditions can never both hold, like "lit" and "not lit",
+// or "score is 1" and "score is 2"
+func contradicts(a blocks.Expression, b blocks.Expression) bool {
+	if a.Token.Type == tokens.NOT && a.Left == nil && a.Right.String() == b.String() {
+		return true
+	}
+	if b.Token.Type == tokens.NOT && b.Left == nil && b.Right.String() == a.String() {
+		return true
+	}
+
+	return a.Token.Type == tokens.IS && b.Token.Type == tokens.IS &&
+		a.Left.String() == b.Left.String() &&
+		isLiteral(*a.Right) && isLiteral(*b.Right) &&
+		!literalsEqual(*a.Right, *b.Right)
+}
+
+func headerKey(block blocks.Block) string {
+	var parts []string
+	for _, expr := range block.Header {
+		parts = append(parts, expr.String())
+	}
+
+	if block.Type == blocks.INPUT {
+		slices.Sort(parts)
+		return "> " + strings.Join(parts, " ")
+	}
+	return "= " + strings.Join(parts, " ")
+}
+
+func (c *reachChecker) newNode(block blocks.Block, parent *reachNode) *reachNode {
+	node := &reachNode{
+		block: block,
+		parent: parent,
+		order: len(c.nodes),
+		names: make(map[string]bool),
+		conditions: make(map[string]requirement),
+		key: headerKey(block),
+	}
+
+	if parent != nil {
+		node.depth = parent.depth + 1
+		node.inputs = parent.inputs
+		node.matched = parent.matched
+		node.anys = slices.Clone(parent.anys)
+		node.key = parent.key + "\n" + node.key
+
+		for name := range parent.names {
+			node.names[name] = true
+		}
+		for key, req := range parent.conditions {
+			node.conditions[key] = req
+		}
+	}
+
+	return node
+}
+
+// Returns the condition this block's header contradicts, if any
+func (c *reachChecker) addConditions(node *reachNode) (blocks.Expression, requirement, bool) {
+	if node.block.Type != blocks.STATE {
+		return blocks.Expression{}, requirement{}, false
+	}
+
+	for _, header := range node.block.Header {
+		for _, expr := range splitConditions(header, nil) {
+			for _, req := range node.conditions {
+				if contradicts(expr, req.expr) {
+					return expr, req, true
+				}
+			}
+			node.conditions[conditionKey(expr, node)] = requirement{expr: expr, owner: node}
+		}
+	}
+	return blocks.Expression{}, requirement{}, false
+}
+
+func (c *reachChecker) addInputs(node *reachNode) {
+	node.matched = true
+
+	for _, expr := range node.block.Header {
+		if blocks.IsBareName(expr) && expr.Token.Literal == ANY {
+			node.anys = append(node.anys, node)
+		} else {
+			node.names[expr.Token.Literal] = true
+			node.inputs++
+		}
+	}
+}
+
+// "any" blocks only match inputs which match none of their input block
+// siblings, so a block inside one which requires every alias of such a
+// sibling can never be triggered
+func (c *reachChecker) findAnySibling(node *reachNode, siblings map[*reachNode][]*reachNode) (*reachNode, *reachNode) {
+	for _, anyNode := range node.anys {
+		for _, sibling := range siblings[anyNode] {
+			covered := true
+			for name := range sibling.names {
+				if !node.names[name] {
+					covered = false
+					break
+				}
+			}
+
+			if covered && len(sibling.names) > 0 {
+				return anyNode, sibling
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (c *reachChecker) walk(taleBlocks []blocks.Block, parent *reachNode, siblings map[*reachNode][]*reachNode) {
+	var nodes []*reachNode
+	var anys []*reachNode
+
+	for _, block := range taleBlocks {
+		if block.Type != blocks.INPUT && block.Type != blocks.STATE {
+			continue
+		}
+
+		node := c.newNode(block, parent)
+		c.nodes = append(c.nodes, node)
+		nodes = append(nodes, node)
+
+		if block.Type == blocks.INPUT {
+			c.addInputs(node)
+			if len(node.anys) > 0 && node.anys[len(node.anys) - 1] == node {
+				anys = append(anys, node)
+			}
+		}
+	}
+
+	// Record each any block's input siblings before checking any nested
+	// blocks, since siblings may come after the any block
+	for _, anyNode := range anys {
+		for _, node := range nodes {
+			if node.block.Type == blocks.INPUT && node != anyNode && !slices.Contains(node.anys, node) {
+				siblings[anyNode] = append(siblings[anyNode], node)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if expr, req, ok := c.addConditions(node); ok {
+			c.warnf(node, "this block can never be triggered, %q contradicts %q at %s", expr.Format(), req.expr.Format(), c.position(req.owner))
+			node.matched = false
+			continue
+		}
+
+		if anyNode, sibling := c.findAnySibling(node, siblings); anyNode != nil {
+			c.warnf(node, "this block can never be triggered, the \"any\" block at %s skips every input which matches the block at %s", c.position(anyNode), c.position(sibling))
+			node.matched = false
+			continue
+		}
+
+		c.walk(node.block.ChildBlocks, node, siblings)
+	}
+}
+
+// Whether a block is triggered whenever another is, and always outranks it
+func (n *reachNode) shadows(other *reachNode) bool {
+	if n == other || !n.matched || other.hasAncestor(n) {
+		return false
+	}
+
+	for name := range n.names {
+		if !other.names[name] {
+			return false
+		}
+	}
+	for key := range n.conditions {
+		if _, ok := other.conditions[key]; !ok {
+			return false
+		}
+	}
+	for _, anyNode := range n.anys {
+		if anyNode != other && !other.hasAncestor(anyNode) {
+			return false
+		}
+	}
+
+	switch {
+	case n.inputs != other.inputs:
+		return n.inputs > other.inputs
+	case n.depth != other.depth:
+		return n.depth > other.depth
+	default:
+		return n.order < other.order
+	}
+}
+
+func (c *reachChecker) checkShadows() {
+	for _, b := range c.nodes {
+		if !b.matched || len(b.block.Body) == 0 {
+			continue
+		}
+
+		for _, a := range c.nodes {
+			if !a.shadows(b) {
+				continue
+			}
+
+			if a.key == b.key {
+				c.warnf(b, "this block duplicates the block at %s, which always takes priority, so it will never be triggered", c.position(a))
+			} else {
+				c.warnf(b, "this block will never be triggered, the block at %s matches every input it does and always takes priority", c.position(a))
+			}
+			break
+		}
+	}
+}
+
+// Finds blocks which can never be triggered, either because their
+// conditions contradict each other or because another block is always
+// selected instead, reporting the location of that other block
+func Reachability(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &reachChecker{root: blocks.CommonDir(taleBlocks)}
+	c.walk(taleBlocks, nil, make(map[*reachNode][]*reachNode))
+	c.checkShadows()
+	return c.diagnostics
+}
@@ -0,0 +1,165 @@
+package check
+
+import (
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/state"
+	"tale/tokens"
+)
+
+// A value set by a start block, with where it was set
+type startValue struct {
+	value state.Value
+	path string
+	token tokens.Token
+}
+
+type startChecker struct {
+	objects map[string]bool
+	values map[string]startValue
+	root string
+	path string
+	diagnostics []diagnostics.Diagnostic
+}
+
+func literalValue(expr blocks.Expression) (state.Value, bool) {
+	if !isLiteral(expr) {
+		return state.Value{}, false
+	}
+
+	switch expr.Token.Type {
+	case tokens.NUMBER:
+		number, err := state.ParseNum(expr.Token.Literal)
+		return state.Number(number), err == nil
+	case tokens.FLAG:
+		return state.Flag(state.ParseFlag(expr.Token.Literal)), true
+	default:
+		return state.Text(expr.Token.Literal), true
+	}
+}
+
+func (c *startChecker) isObject(expr blocks.Expression) bool {
+	return blocks.IsBareName(expr) && c.objects[expr.Token.Literal]
+}
+
+func valueKey(object blocks.Expression, value string) string {
+	return value + " of " + object.Token.Literal
+}
+
+// Names a variable, or a value of an object named directly, the way the
+// game does, such as "score" or "location of lamp"
+func (c *startChecker) getKey(expr blocks.Expression) string {
+	switch {
+	case blocks.IsBareName(expr) && !c.isObject(expr):
+		return expr.Token.Literal
+	case expr.Token.Type == tokens.OF && blocks.IsBareName(*expr.Left) && c.isObject(*expr.Right):
+		return valueKey(*expr.Right, expr.Left.Token.Literal)
+	case expr.Token.Type == tokens.COLON && c.isObject(*expr.Left) && blocks.IsBareName(*expr.Right):
+		return valueKey(*expr.Left, expr.Right.Token.Literal)
+	default:
+		return ""
+	}
+}
+
+func (c *startChecker) setInitial(token tokens.Token, key string, value state.Value) {
+	if key == "" {
+		return
+	}
+
+	prev, ok := c.values[key]
+	if ok && prev.path != c.path && !prev.value.Equals(value) {
+		c.diagnostics = append(c.diagnostics, diagnostics.NewWarning(
+			c.path,
+			token,
+			"%s is set to %s here, but the start block at %s set it to %s, start blocks in different files should not disagree",
+			key, value, position(c.root, prev.path, prev.token), prev.value,
+		))
+	}
+
+	c.values[key] = startValue{value: value, path: c.path, token: token}
+}
+
+func (c *startChecker) place(token tokens.Token, object blocks.Expression, location blocks.Expression) {
+	if c.isObject(object) && blocks.IsBareName(location) {
+		c.setInitial(token, valueKey(object, state.LOCATION), state.ObjectRef(location.Token.Literal))
+	}
+}
+
+// Mirrors the forms of set handled by the game, skipping any whose value
+// is only known once the game runs
+func (c *startChecker) checkSet(node blocks.BodyNode) {
+	args := node.Args
+	if node.Type == blocks.ENCLOSING_NODE {
+		return
+	}
+
+	if len(args) == 2 {
+		if value, ok := literalValue(args[1]); ok {
+			c.setInitial(args[0].Token, c.getKey(args[0]), value)
+		}
+		return
+	}
+
+	if len(args) != 1 {
+		return
+	}
+
+	expr := args[0]
+	negated := expr.Token.Type == tokens.NOT
+	if negated {
+		expr = *expr.Right
+	}
+
+	switch {
+	case expr.Token.Type == tokens.IS && c.isObject(*expr.Left) && blocks.IsBareName(*expr.Right) && !c.isObject(*expr.Right):
+		c.setInitial(expr.Token, valueKey(*expr.Left, expr.Right.Token.Literal), state.Flag(!negated))
+	case negated:
+	case expr.Token.Type == tokens.IS:
+		if value, ok := literalValue(*expr.Right); ok {
+			c.setInitial(expr.Token, c.getKey(*expr.Left), value)
+		}
+	case expr.Token.Type == tokens.IN:
+		c.place(expr.Token, *expr.Left, *expr.Right)
+	default:
+		c.setInitial(expr.Token, c.getKey(expr), state.Flag(true))
+	}
+}
+
+// Only actions directly in a start block's text are compared, since
+// actions inside "if" or choices may never run
+func (c *startChecker) checkBody(body []blocks.BodyNode) {
+	for _, node := range body {
+		switch node.Name {
+		case "set":
+			c.checkSet(node)
+		case "place":
+			if len(node.Args) == 2 {
+				c.place(node.Token, node.Args[0], node.Args[1])
+			}
+		case "name":
+			if len(node.Args) == 2 && node.Type == blocks.ACTION_NODE && c.isObject(node.Args[0]) && node.Args[1].Token.Type == tokens.TEXT {
+				c.setInitial(node.Token, valueKey(node.Args[0], state.NAME), state.Text(node.Args[1].Token.Literal))
+			}
+		}
+	}
+}
+
+// Finds start blocks in different files which set the same variable or
+// object value to different values, which the game would only warn about
+// once it starts. Blocks must be in load order.
+func Starts(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &startChecker{
+		objects: findObjects(taleBlocks),
+		values: make(map[string]startValue),
+		root: blocks.CommonDir(taleBlocks),
+	}
+
+	for _, block := range taleBlocks {
+		if block.Type == blocks.START {
+			c.path = block.Path
+			c.checkBody(block.Body)
+		}
+	}
+
+	return c.diagnostics
+}
@@ -0,0 +1,413 @@
+package check
+
+import (
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/state"
+	"tale/tokens"
+)
+
+// Object values which game engines use, so they need not be read by a tale
+var engineValues = map[string]bool{
+	state.NAME: true,
+	state.LOCATION: true,
+	"description": true,
+	"link": true,
+	"color": true,
+	"image": true,
+	"image_icon": true,
+	"image_hero": true,
+	"image_background": true,
+	"sound": true,
+	"sound_background": true,
+}
+
+// Variables the engine sets, so they need not be set by a tale
+var engineVariables = map[string]state.ValueType{
+	state.REPEAT: state.FLAG,
+	state.REPEATS: state.NUMBER,
+}
+
+type site struct {
+	path string
+	token tokens.Token
+}
+
+// A variable, or a value of an object. Objects are only tracked when they
+// are referred to by name.
+type variable struct {
+	label string
+	valueType state.ValueType
+	sets []site
+	reads []site
+	engineSet bool
+	engineRead bool
+}
+
+// A set action, with either a value to infer the type from or a fixed type
+type assignment struct {
+	key string
+	value *blocks.Expression
+	valueType state.ValueType
+	site site
+}
+
+type typeChecker struct {
+	objects map[string]bool
+	variables map[string]*variable
+	keys []string
+	assignments []assignment
+	checked []site
+	checkedExprs []blocks.Expression
+	root string
+	path string
+	diagnostics []diagnostics.Diagnostic
+}
+
+func (c *typeChecker) errorf(s site, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, diagnostics.New(s.path, s.token, format, args...))
+}
+
+func (c *typeChecker) site(token tokens.Token) site {
+	return site{path: c.path, token: token}
+}
+
+func (c *typeChecker) isObject(expr *blocks.Expression) bool {
+	return expr != nil && blocks.IsBareName(*expr) && c.objects[expr.Token.Literal]
+}
+
+func (c *typeChecker) getVariable(key string, label string) *variable {
+	v, ok := c.variables[key]
+	if !ok {
+		v = &variable{label: label}
+		c.variables[key] = v
+		c.keys = append(c.keys, key)
+	}
+	return v
+}
+
+// Returns the key for a variable or a value of an object named directly,
+// or "" if the expression refers to neither
+func (c *typeChecker) getKey(expr blocks.Expression) (string, *variable) {
+	switch {
+	case blocks.IsBareName(expr) && !c.objects[expr.Token.Literal]:
+		name := expr.Token.Literal
+		v := c.getVariable(name, name)
+		if valueType, ok := engineVariables[name]; ok {
+			v.engineSet = true
+			v.valueType = valueType
+		}
+		return name, v
+
+	case expr.Token.Type == tokens.OF && blocks.IsBareName(*expr.Left) && c.isObject(expr.Right):
+		return c.getValueKey(expr.Right.Token.Literal, expr.Left.Token.Literal)
+
+	case expr.Token.Type == tokens.COLON && c.isObject(expr.Left) && blocks.IsBareName(*expr.Right):
+		return c.getValueKey(expr.Left.Token.Literal, expr.Right.Token.Literal)
+
+	default:
+		return "", nil
+	}
+}
+
+func (c *typeChecker) getValueKey(object string, value string) (string, *variable) {
+	key := object + ":" + value
+	v := c.getVariable(key, value + " of " + object)
+
+	if engineValues[value] {
+		v.engineRead = true
+	}
+
+	switch value {
+	case state.NAME:
+		v.engineSet = true
+		v.valueType = state.TEXT
+	case state.LOCATION:
+		v.engineSet = true
+		v.valueType = state.OBJECT
+	}
+
+	return key, v
+}
+
+// A flag check like "door is locked", rather than a comparison
+func (c *typeChecker) isFlagCheck(expr blocks.Expression) bool {
+	return expr.Token.Type == tokens.IS &&
+		c.isObject(expr.Left) &&
+		blocks.IsBareName(*expr.Right) &&
+		!c.objects[expr.Right.Token.Literal]
+}
+
+func (c *typeChecker) read(expr *blocks.Expression) {
+	if expr == nil {
+		return
+	}
+
+	if key, v := c.getKey(*expr); key != "" {
+		v.reads = append(v.reads, c.site(firstToken(*expr)))
+		return
+	}
+
+	switch {
+	case c.isFlagCheck(*expr):
+		_, v := c.getValueKey(expr.Left.Token.Literal, expr.Right.Token.Literal)
+		v.reads = append(v.reads, c.site(expr.Right.Token))
+
+	case expr.Token.Type == tokens.OF:
+		c.read(expr.Right)
+
+	case expr.Token.Type == tokens.COLON:
+		c.read(expr.Left)
+
+	default:
+		c.read(expr.Left)
+		c.read(expr.Right)
+		c.checked = append(c.checked, c.site(expr.Token))
+		c.checkedExprs = append(c.checkedExprs, *expr)
+	}
+}
+
+func (c *typeChecker) assign(target blocks.Expression, value *blocks.Expression, valueType state.ValueType) {
+	key, v := c.getKey(target)
+	if key == "" {
+		c.read(&target)
+		return
+	}
+
+	s := c.site(firstToken(target))
+	v.sets = append(v.sets, s)
+	c.assignments = append(c.assignments, assignment{key: key, value: value, valueType: valueType, site: s})
+	c.read(value)
+}
+
+// Mirrors the forms of set handled by the game, reporting the ones it
+// would reject when run
+func (c *typeChecker) checkSet(node blocks.BodyNode) {
+	args := node.Args
+	enclosing := node.Type == blocks.ENCLOSING_NODE
+
+	switch {
+	case len(args) == 1 && enclosing:
+		c.assign(args[0], nil, state.TEXT)
+
+	case len(args) == 2 && !enclosing:
+		c.assign(args[0], &args[1], state.UNSET)
+
+	case len(args) == 1:
+		expr := args[0]
+		negated := expr.Token.Type == tokens.NOT
+		if negated {
+			expr = *expr.Right
+		}
+
+		switch {
+		case c.isFlagCheck(expr):
+			key, v := c.getValueKey(expr.Left.Token.Literal, expr.Right.Token.Literal)
+			s := c.site(expr.Right.Token)
+			v.sets = append(v.sets, s)
+			c.assignments = append(c.assignments, assignment{key: key, valueType: state.FLAG, site: s})
+		case expr.Token.Type == tokens.IS && negated:
+			c.errorf(c.site(expr.Token), "cannot set %s to not a value", expr.Left.Format())
+		case expr.Token.Type == tokens.IS:
+			c.assign(*expr.Left, expr.Right, state.UNSET)
+		case expr.Token.Type == tokens.IN && negated:
+			c.errorf(c.site(expr.Token), "cannot set an object to not be in another, place it somewhere instead")
+		case expr.Token.Type == tokens.IN:
+		case negated:
+			c.errorf(c.site(expr.Token), "use unset to unset %s", expr.Format())
+		default:
+			c.assign(expr, nil, state.FLAG)
+		}
+
+	default:
+		c.errorf(c.site(node.Token), "set expects a variable and optionally a value")
+	}
+}
+
+func (c *typeChecker) checkBody(body []blocks.BodyNode) {
+	for _, node := range body {
+		switch node.Name {
+		case "set":
+			c.checkSet(node)
+
+		case "unset":
+			for _, arg := range node.Args {
+				if key, v := c.getKey(arg); key != "" {
+					v.sets = append(v.sets, c.site(firstToken(arg)))
+				}
+			}
+
+		case "alias":
+			for i := 1; i < len(node.Args); i++ {
+				c.read(&node.Args[i])
+			}
+
+		case "do", "place":
+
+		default:
+			for i := range node.Args {
+				c.read(&node.Args[i])
+			}
+		}
+
+		c.checkBody(node.Children)
+	}
+}
+
+func (c *typeChecker) checkBlocks(taleBlocks []blocks.Block) {
+	for _, block := range taleBlocks {
+		c.path = block.Path
+
+		if block.Type == blocks.STATE {
+			for i := range block.Header {
+				c.read(&block.Header[i])
+			}
+		}
+
+		c.checkBody(block.Body)
+		c.checkBlocks(block.ChildBlocks)
+	}
+}
+
+func (c *typeChecker) typeOf(expr blocks.Expression) state.ValueType {
+	if key, v := c.getKey(expr); key != "" {
+		return v.valueType
+	}
+
+	switch expr.Token.Type {
+	case tokens.NUMBER, tokens.PLUS, tokens.MINUS, tokens.MULTIPLY, tokens.DIVIDE, tokens.REMAINDER:
+		return state.NUMBER
+	case tokens.TEXT:
+		return state.TEXT
+	case tokens.FLAG, tokens.AND, tokens.OR, tokens.NOT, tokens.IS, tokens.HAS, tokens.IN, tokens.WITH,
+		tokens.GT, tokens.LT, tokens.GTE, tokens.LTE:
+		return state.FLAG
+	case tokens.NAME, tokens.IT:
+		return state.OBJECT
+	default:
+		return state.UNSET
+	}
+}
+
+// Infers types until no more can be inferred, since a variable may be set
+// from another variable that is only set later on
+func (c *typeChecker) inferTypes() {
+	for changed := true; changed; {
+		changed = false
+
+		for _, a := range c.assignments {
+			v := c.variables[a.key]
+			if v.valueType != state.UNSET {
+				continue
+			}
+
+			valueType := a.valueType
+			if a.value != nil {
+				valueType = c.typeOf(*a.value)
+			}
+
+			if valueType != state.UNSET {
+				v.valueType = valueType
+				changed = true
+			}
+		}
+	}
+}
+
+// Reports assignments which differ from the first typed assignment in load
+// order, or from the type the engine requires
+func (c *typeChecker) checkAssignments() {
+	type firstAssignment struct {
+		valueType state.ValueType
+		site site
+	}
+	firsts := make(map[string]firstAssignment)
+
+	for _, a := range c.assignments {
+		v := c.variables[a.key]
+
+		valueType := a.valueType
+		if a.value != nil {
+			valueType = c.typeOf(*a.value)
+		}
+
+		if valueType == state.UNSET {
+			continue
+		}
+
+		if v.engineSet {
+			if valueType != v.valueType {
+				c.errorf(a.site, "%s must always be a %s, but is set to a %s here", v.label, v.valueType, valueType)
+			}
+			continue
+		}
+
+		first, ok := firsts[a.key]
+		if !ok {
+			firsts[a.key] = firstAssignment{valueType: valueType, site: a.site}
+		} else if valueType != first.valueType {
+			c.errorf(a.site, "%s is a %s (set at %s), but is set to a %s here, a variable's type may not change", v.label, first.valueType, position(c.root, first.site.path, first.site.token), valueType)
+		}
+	}
+}
+
+func (c *typeChecker) checkNumber(s site, operator string, expr *blocks.Expression) {
+	if expr == nil {
+		return
+	}
+
+	if valueType := c.typeOf(*expr); valueType != state.NUMBER && valueType != state.UNSET {
+		c.errorf(s, "%s only works with numbers, but %s is a %s", operator, expr.Format(), valueType)
+	}
+}
+
+func (c *typeChecker) checkOperators() {
+	for i, expr := range c.checkedExprs {
+		s := c.checked[i]
+
+		switch expr.Token.Type {
+		case tokens.PLUS, tokens.MINUS, tokens.MULTIPLY, tokens.DIVIDE, tokens.REMAINDER,
+			tokens.GT, tokens.LT, tokens.GTE, tokens.LTE:
+			c.checkNumber(s, expr.Token.Literal, expr.Left)
+			c.checkNumber(s, expr.Token.Literal, expr.Right)
+
+		case tokens.IS:
+			left, right := c.typeOf(*expr.Left), c.typeOf(*expr.Right)
+			if left != state.UNSET && right != state.UNSET && left != right {
+				c.errorf(s, "%s is a %s and %s is a %s, so they can never be the same", expr.Left.Format(), left, expr.Right.Format(), right)
+			}
+		}
+	}
+}
+
+func (c *typeChecker) checkUsage() {
+	for _, key := range c.keys {
+		v := c.variables[key]
+
+		switch {
+		case len(v.sets) == 0 && len(v.reads) > 0 && !v.engineSet:
+			c.errorf(v.reads[0], "%s is used but never set, set it with {set %s}", v.label, v.label)
+		case len(v.reads) == 0 && len(v.sets) > 0 && !v.engineRead:
+			c.errorf(v.sets[0], "%s is set but never used", v.label)
+		}
+	}
+}
+
+// Checks the rules for variables from the overview across every block in
+// a tale: a variable's type never changes, and every variable is both set
+// and used. Types are inferred from set actions, literals, and arithmetic.
+func Types(taleBlocks []blocks.Block) []diagnostics.Diagnostic {
+	c := &typeChecker{
+		objects: findObjects(taleBlocks),
+		variables: make(map[string]*variable),
+		root: blocks.CommonDir(taleBlocks),
+	}
+
+	c.checkBlocks(taleBlocks)
+	c.inferTypes()
+	c.checkAssignments()
+	c.checkOperators()
+	c.checkUsage()
+
+	return c.diagnostics
+}
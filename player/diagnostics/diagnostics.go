@@ -0,0 +1,62 @@
+package diagnostics
+
+import (
+	"fmt"
+	"tale/tokens"
+)
+
+type Severity uint8
+
+const (
+	ERROR Severity = iota
+	WARNING
+)
+
+func (s Severity) String() string {
+	switch s {
+	case ERROR: return "error"
+	case WARNING: return "warning"
+	default: return "unknown"
+	}
+}
+
+// A problem found in a tale file. Line and Column are 0 when the problem
+// applies to the whole file, such as when it cannot be read, and Column
+// alone is 0 when it applies to a whole line.
+type Diagnostic struct {
+	Path string
+	Line int
+	Column int
+	Severity Severity
+	Message string
+}
+
+func New(path string, token tokens.Token, format string, args ...any) Diagnostic {
+	return Diagnostic{
+		Path: path,
+		Line: token.Line,
+		Column: token.Column,
+		Severity: ERROR,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func NewWarning(path string, token tokens.Token, format string, args ...any) Diagnostic {
+	d := New(path, token, format, args...)
+	d.Severity = WARNING
+	return d
+}
+
+func (d Diagnostic) IsError() bool {
+	return d.Severity == ERROR
+}
+
+func (d Diagnostic) Error() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", d.Path, d.Severity, d.Message)
+	}
+	if d.Column == 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", d.Path, d.Line, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.Path, d.Line, d.Column, d.Severity, d.Message)
+}
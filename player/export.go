@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"tale/blocks"
+	"tale/export"
+	"tale/loader"
+)
+
+func exportTale(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "json", "export format: json or taelmoor")
+	output := flags.String("output", "", "file to write to (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tale export [--format json|taelmoor] [--output file] [directory or .tale file]...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var write func(io.Writer, []blocks.Block) error
+	switch *format {
+	case "json":
+		write = export.WriteJSON
+	case "taelmoor":
+		write = export.WriteTaelmoor
+	default:
+		log.Fatalf("Error: Unknown export format %q\n", *format)
+	}
+
+	files := loader.Load(findTalePaths(flags.Args()))
+	if !reportErrors(files) {
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w, loader.Blocks(files)); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,211 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"tale/blocks"
+	"tale/state"
+	"tale/tokens"
+)
+
+// Incremented whenever the JSON format changes in a way that could break
+// existing engines. See docs/json-format.md for the full format.
+const JSON_VERSION = 1
+
+type jsonTale struct {
+	Format string `json:"format"`
+	Version int `json:"version"`
+	Files []jsonFile `json:"files"`
+}
+
+type jsonFile struct {
+	Path string `json:"path"`
+	Blocks []jsonBlock `json:"blocks"`
+}
+
+type jsonBlock struct {
+	Type string `json:"type"`
+	Line int `json:"line"`
+	Column int `json:"column"`
+	Header []jsonExpression `json:"header,omitempty"`
+	It string `json:"it,omitempty"`
+	Body []jsonNode `json:"body"`
+	Blocks []jsonBlock `json:"blocks"`
+}
+
+type jsonExpression struct {
+	Type string `json:"type"`
+	Line int `json:"line"`
+	Column int `json:"column"`
+	Value any `json:"value,omitempty"`
+	Left *jsonExpression `json:"left,omitempty"`
+	Right *jsonExpression `json:"right,omitempty"`
+}
+
+type jsonNode struct {
+	Type string `json:"type"`
+	Line int `json:"line"`
+	Column int `json:"column"`
+	Text string `json:"text,omitempty"`
+	Name string `json:"name,omitempty"`
+	Args []jsonExpression `json:"args,omitempty"`
+	Children []jsonNode `json:"children,omitempty"`
+}
+
+func getBlockType(bt blocks.BlockType) string {
+	switch bt {
+	case blocks.START: return "start"
+	case blocks.INPUT: return "input"
+	case blocks.STATE: return "state"
+	default: return "invalid"
+	}
+}
+
+func getNodeType(nt blocks.BodyNodeType) string {
+	switch nt {
+	case blocks.TEXT_NODE: return "text"
+	case blocks.ACTION_NODE: return "action"
+	case blocks.INTERPOLATION_NODE: return "interpolation"
+	case blocks.ENCLOSING_NODE: return "enclosing"
+	default: return "invalid"
+	}
+}
+
+func getExpressionType(t tokens.TokenType) string {
+	switch t {
+	case tokens.NAME: return "name"
+	case tokens.TEXT: return "text"
+	case tokens.NUMBER: return "number"
+	case tokens.FLAG: return "flag"
+	case tokens.IT: return "it"
+	case tokens.COLON: return ":"
+	case tokens.PLUS: return "+"
+	case tokens.MINUS: return "-"
+	case tokens.MULTIPLY: return "*"
+	case tokens.DIVIDE: return "/"
+	case tokens.REMAINDER: return "%"
+	case tokens.GT: return ">"
+	case tokens.LT: return "<"
+	case tokens.GTE: return ">="
+	case tokens.LTE: return "<="
+	case tokens.IS: return "is"
+	case tokens.HAS: return "has"
+	case tokens.IN: return "in"
+	case tokens.OF: return "of"
+	case tokens.WITH: return "with"
+	case tokens.AND: return "and"
+	case tokens.OR: return "or"
+	case tokens.NOT: return "not"
+	default: return "invalid"
+	}
+}
+
+func getExpressionValue(token tokens.Token) any {
+	switch token.Type {
+	case tokens.NAME, tokens.TEXT:
+		return token.Literal
+	case tokens.NUMBER:
+		if number, err := state.ParseNum(token.Literal); err == nil {
+			return number
+		}
+		return nil
+	case tokens.FLAG:
+		return state.ParseFlag(token.Literal)
+	default:
+		return nil
+	}
+}
+
+func toJsonExpression(expr blocks.Expression) jsonExpression {
+	j := jsonExpression{
+		Type: getExpressionType(expr.Token.Type),
+		Line: expr.Token.Line,
+		Column: expr.Token.Column,
+		Value: getExpressionValue(expr.Token),
+	}
+
+	if expr.Left != nil {
+		left := toJsonExpression(*expr.Left)
+		j.Left = &left
+	}
+	if expr.Right != nil {
+		right := toJsonExpression(*expr.Right)
+		j.Right = &right
+	}
+
+	return j
+}
+
+func toJsonExpressions(exprs []blocks.Expression) []jsonExpression {
+	var j []jsonExpression
+	for _, expr := range exprs {
+		j = append(j, toJsonExpression(expr))
+	}
+	return j
+}
+
+func toJsonNodes(body []blocks.BodyNode) []jsonNode {
+	j := []jsonNode{}
+
+	for _, node := range body {
+		jn := jsonNode{
+			Type: getNodeType(node.Type),
+			Line: node.Token.Line,
+			Column: node.Token.Column,
+			Text: node.Text,
+			Name: node.Name,
+			Args: toJsonExpressions(node.Args),
+		}
+
+		if node.Type == blocks.ENCLOSING_NODE {
+			jn.Children = toJsonNodes(node.Children)
+		}
+
+		j = append(j, jn)
+	}
+
+	return j
+}
+
+func toJsonBlocks(taleBlocks []blocks.Block) []jsonBlock {
+	j := []jsonBlock{}
+
+	for _, block := range taleBlocks {
+		j = append(j, jsonBlock{
+			Type: getBlockType(block.Type),
+			Line: block.Line,
+			Column: block.Column,
+			Header: toJsonExpressions(block.Header),
+			It: block.It,
+			Body: toJsonNodes(block.Body),
+			Blocks: toJsonBlocks(block.ChildBlocks),
+		})
+	}
+
+	return j
+}
+
+// Writes the blocks as JSON, grouped by file in the order each file first
+// appears. File paths are written relative to the directory containing
+// every file of the tale, using forward slashes.
+func WriteJSON(w io.Writer, taleBlocks []blocks.Block) error {
+	blocks.ResolveIt(taleBlocks)
+	root := blocks.CommonDir(taleBlocks)
+	tale := jsonTale{Format: "tale-maker", Version: JSON_VERSION, Files: []jsonFile{}}
+	fileIndexes := make(map[string]int)
+
+	for _, block := range taleBlocks {
+		i, ok := fileIndexes[block.Path]
+		if !ok {
+			i = len(tale.Files)
+			fileIndexes[block.Path] = i
+			tale.Files = append(tale.Files, jsonFile{Path: blocks.RelativePath(root, block.Path)})
+		}
+
+		tale.Files[i].Blocks = append(tale.Files[i].Blocks, toJsonBlocks([]blocks.Block{block})...)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tale)
+}
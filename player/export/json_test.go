@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"tale/parser"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	dir := t.TempDir()
+	taleBlocks := parser.MustParse(t, path.Join(dir, "start.tale"), "{set debt -1,000.5}\n> greet >\n== door is not locked ==\nHi {b}{name of player}{/b}")
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, taleBlocks); err != nil {
+		t.Fatal(err)
+	}
+
+	var tale jsonTale
+	if err := json.Unmarshal(buf.Bytes(), &tale); err != nil {
+		t.Fatal(err)
+	}
+
+	if tale.Format != "tale-maker" || tale.Version != JSON_VERSION || len(tale.Files) != 1 {
+		t.Fatalf("unexpected tale %+v", tale)
+	}
+
+	file := tale.Files[0]
+	if file.Path != "start.tale" || len(file.Blocks) != 2 {
+		t.Fatalf("unexpected file %+v", file)
+	}
+
+	start := file.Blocks[0]
+	debt := start.Body[0].Args[1]
+	if start.Type != "start" || debt.Type != "-" || debt.Left != nil || debt.Right.Value != 1000.5 {
+		t.Fatalf("unexpected start block %+v", start)
+	}
+
+	greet := file.Blocks[1]
+	if greet.Type != "input" || greet.Line != 2 || greet.Header[0].Value != "greet" || greet.It != "" || len(greet.Blocks) != 1 {
+		t.Fatalf("unexpected input block %+v", greet)
+	}
+
+	locked := greet.Blocks[0]
+	condition := locked.Header[0]
+	if locked.Type != "state" || condition.Type != "not" || condition.Right.Type != "is" || condition.Right.Left.Value != "door" || locked.It != "door" {
+		t.Fatalf("unexpected state block %+v", locked)
+	}
+
+	bold := locked.Body[1]
+	if bold.Type != "enclosing" || bold.Name != "b" || bold.Column != 4 || bold.Children[0].Args[0].Type != "of" {
+		t.Fatalf("unexpected body node %+v", bold)
+	}
+}
@@ -0,0 +1,257 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"tale/blocks"
+	"tale/tokens"
+)
+
+// Incremented whenever the Taelmoor bundle changes in a way that could
+// break the app. See docs/taelmoor-format.md for the full format.
+const TAELMOOR_VERSION = 1
+
+// Taelmoor QR codes are two lowercase letters or digits, like "cx" or "d1"
+var qrCodePattern = regexp.MustCompile(`^[a-z0-9]{2}$`)
+
+type taelmoorScenario struct {
+	Format string `json:"format"`
+	Version int `json:"version"`
+	Name string `json:"name"`
+	Codes map[string][]string `json:"codes"`
+	Names map[string]string `json:"names"`
+	Entries []taelmoorEntry `json:"entries"`
+}
+
+type taelmoorEntry struct {
+	Source string `json:"source"`
+	Inputs []string `json:"inputs"`
+	Conditions []string `json:"conditions"`
+	Label string `json:"label,omitempty"`
+	Text string `json:"text"`
+	Effects []taelmoorEffect `json:"effects"`
+}
+
+type taelmoorEffect struct {
+	Action string `json:"action"`
+	Args []string `json:"args"`
+}
+
+// Files named like "tale-player-only.tale" only apply to the target named
+// before "-only", while all other files apply to every target
+func IsFileForTarget(path string, target string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), ".tale")
+	if !strings.HasSuffix(name, "-only") {
+		return true
+	}
+	return strings.TrimSuffix(name, "-only") == target
+}
+
+type taelmoorExporter struct {
+	root string
+	scenario taelmoorScenario
+}
+
+func formatArgs(args []blocks.Expression) []string {
+	formatted := []string{}
+	for _, arg := range args {
+		formatted = append(formatted, arg.Format())
+	}
+	return formatted
+}
+
+func getTextArg(args []blocks.Expression) string {
+	if len(args) > 0 && args[len(args) - 1].Token.Type == tokens.TEXT {
+		return args[len(args) - 1].Token.Literal
+	}
+	return ""
+}
+
+func isNameOf(args []blocks.Expression) bool {
+	return len(args) == 1 && args[0].Token.Type == tokens.OF &&
+		args[0].Left.Token.Literal == "name" && blocks.IsBareName(*args[0].Right)
+}
+
+func findAlias(body []blocks.BodyNode) string {
+	for _, node := range body {
+		if node.Name == "alias" && len(node.Args) > 0 {
+			return node.Args[0].Token.Literal
+		}
+		if alias := findAlias(node.Children); alias != "" {
+			return alias
+		}
+	}
+	return ""
+}
+
+func (e *taelmoorExporter) collectAliases(body []blocks.BodyNode) {
+	for _, node := range body {
+		if node.Name != "alias" || len(node.Args) == 0 {
+			e.collectAliases(node.Children)
+			continue
+		}
+
+		name := node.Args[0].Token.Literal
+		var list []string
+
+		for _, arg := range node.Args[1:] {
+			if arg.Token.Type == tokens.TEXT {
+				list = append(list, strings.Fields(arg.Token.Literal)...)
+			}
+		}
+		for _, child := range node.Children {
+			list = append(list, strings.Fields(child.Text)...)
+		}
+
+		for _, code := range list {
+			if qrCodePattern.MatchString(code) && !slices.Contains(e.scenario.Codes[name], code) {
+				e.scenario.Codes[name] = append(e.scenario.Codes[name], code)
+			}
+		}
+	}
+}
+
+func (e *taelmoorExporter) collectBlockAliases(taleBlocks []blocks.Block) {
+	for _, block := range taleBlocks {
+		e.collectAliases(block.Body)
+		e.collectBlockAliases(block.ChildBlocks)
+	}
+}
+
+// Renders display text using the Taelmoor markup for styles and pieces,
+// collecting any effects on game state separately
+func (e *taelmoorExporter) render(body []blocks.BodyNode, entry *taelmoorEntry) string {
+	var sb strings.Builder
+
+	for _, node := range body {
+		args := formatArgs(node.Args)
+
+		switch {
+		case node.Type == blocks.TEXT_NODE:
+			sb.WriteString(node.Text)
+
+		// Taelmoor displays names with {name handler}
+		case node.Type == blocks.INTERPOLATION_NODE && isNameOf(node.Args):
+			sb.WriteString("{name " + node.Args[0].Right.Token.Literal + "}")
+
+		case node.Type == blocks.INTERPOLATION_NODE:
+			sb.WriteString("{" + strings.Join(args, " ") + "}")
+
+		case node.Name == "piece":
+			alias := findAlias(node.Children)
+			if codes := e.scenario.Codes[alias]; len(codes) > 0 {
+				sb.WriteString("<piece>" + codes[0] + "</piece>")
+			} else {
+				sb.WriteString("<piece>" + e.render(node.Children, entry) + "</piece>")
+			}
+
+		// Taelmoor includes content from other entries with {pull barrel "details"}
+		case node.Name == "pull":
+			sb.WriteString("{" + strings.Join(append([]string{node.Name}, args...), " ") + "}")
+
+		case node.Name == "alias":
+			// Aliases only become QR codes
+
+		case node.Name == "name" && node.Type == blocks.ENCLOSING_NODE,
+			node.Name == "set" && node.Type == blocks.ENCLOSING_NODE:
+			text := `"` + e.render(node.Children, entry) + `"`
+			entry.Effects = append(entry.Effects, taelmoorEffect{Action: node.Name, Args: append(args, text)})
+
+		case node.Name == "set", node.Name == "unset", node.Name == "place",
+			node.Name == "name", node.Name == "retain_turn":
+			entry.Effects = append(entry.Effects, taelmoorEffect{Action: node.Name, Args: args})
+
+		case node.Type == blocks.ENCLOSING_NODE:
+			open := strings.Join(append([]string{node.Name}, args...), " ")
+			sb.WriteString("<" + open + ">" + e.render(node.Children, entry) + "</" + node.Name + ">")
+
+		default:
+			sb.WriteString("<" + strings.Join(append([]string{node.Name}, args...), " ") + ">")
+		}
+	}
+
+	return sb.String()
+}
+
+func (e *taelmoorExporter) collectNames(body []blocks.BodyNode) {
+	for _, node := range body {
+		if node.Name == "name" && len(node.Args) > 0 && node.Args[0].Token.Type == tokens.NAME {
+			name := getTextArg(node.Args[1:])
+			if node.Type == blocks.ENCLOSING_NODE {
+				name = strings.TrimSpace(e.render(node.Children, &taelmoorEntry{}))
+			}
+			e.scenario.Names[node.Args[0].Token.Literal] = name
+		}
+	}
+}
+
+func (e *taelmoorExporter) addEntries(taleBlocks []blocks.Block, parent taelmoorEntry) {
+	for _, block := range taleBlocks {
+		entry := taelmoorEntry{
+			Inputs: slices.Clip(parent.Inputs),
+			Conditions: slices.Clip(parent.Conditions),
+			Label: parent.Label,
+			Effects: []taelmoorEffect{},
+		}
+
+		entry.Source = blocks.RelativePath(e.root, block.Path)
+
+		switch block.Type {
+		case blocks.START:
+			e.collectNames(block.Body)
+		case blocks.INPUT:
+			for _, expr := range block.Header {
+				entry.Inputs = append(entry.Inputs, expr.Token.Literal)
+			}
+		case blocks.STATE:
+			if len(block.Header) == 1 && block.Header[0].Token.Type == tokens.TEXT {
+				entry.Label = block.Header[0].Token.Literal
+			} else {
+				entry.Conditions = append(entry.Conditions, formatArgs(block.Header)...)
+			}
+		}
+
+		entry.Text = strings.TrimSpace(e.render(block.Body, &entry))
+		if entry.Text != "" || len(entry.Effects) > 0 {
+			e.scenario.Entries = append(e.scenario.Entries, entry)
+		}
+
+		e.addEntries(block.ChildBlocks, entry)
+	}
+}
+
+// Writes blocks as a Taelmoor scenario bundle. Aliases with two character
+// entries become QR codes, and nested input blocks become entries for
+// using an action on a target. Files only for other targets are skipped.
+func WriteTaelmoor(w io.Writer, taleBlocks []blocks.Block) error {
+	e := &taelmoorExporter{
+		root: blocks.CommonDir(taleBlocks),
+		scenario: taelmoorScenario{
+			Format: "taelmoor-scenario",
+			Version: TAELMOOR_VERSION,
+			Codes: make(map[string][]string),
+			Names: make(map[string]string),
+			Entries: []taelmoorEntry{},
+		},
+	}
+
+	var included []blocks.Block
+	for _, block := range taleBlocks {
+		if IsFileForTarget(block.Path, "taelmoor") {
+			included = append(included, block)
+		}
+	}
+
+	e.collectBlockAliases(included)
+	e.addEntries(included, taelmoorEntry{Inputs: []string{}, Conditions: []string{}})
+	e.scenario.Name = e.scenario.Names["tale"]
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(e.scenario)
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"tale/blocks"
+	"tale/loader"
+	"tale/parser"
+	"testing"
+)
+
+func TestIsFileForTarget(t *testing.T) {
+	if !IsFileForTarget("/tales/start.tale", "taelmoor") ||
+		!IsFileForTarget("/tales/taelmoor-only.tale", "taelmoor") ||
+		IsFileForTarget("/tales/tale-player-only.tale", "taelmoor") {
+		t.Fatalf("unexpected target files")
+	}
+}
+
+func TestWriteTaelmoor(t *testing.T) {
+	dir := t.TempDir()
+
+	var taleBlocks []blocks.Block
+	taleBlocks = append(taleBlocks, parser.MustParse(t, filepath.Join(dir, "start.tale"), `{name tale "Initiation Test"}
+{name handler}Alder Mink{/name}
+
+> entrance_door >
+>> examine >>
+{title}A Rusty Door{/title} {name of handler} {piece}{alias handler}{/piece} watches.
+{pull "wasted action"}
+
+=== player is not strong ===
+{retain_turn yes}{set entrance_door is open}
+Nope.
+
+= "wasted action" =
+{i}(No turn used.){/i}`)...)
+	taleBlocks = append(taleBlocks, parser.MustParse(t, filepath.Join(dir, "taelmoor-only.tale"), `{alias examine "cx"}
+{alias use_item "use item"}
+{alias handler}ec{/alias}`)...)
+	taleBlocks = append(taleBlocks, parser.MustParse(t, filepath.Join(dir, "tale-player-only.tale"), `{alias examine}look go{/alias}`)...)
+
+	var buf bytes.Buffer
+	if err := WriteTaelmoor(&buf, taleBlocks); err != nil {
+		t.Fatal(err)
+	}
+
+	var scenario taelmoorScenario
+	if err := json.Unmarshal(buf.Bytes(), &scenario); err != nil {
+		t.Fatal(err)
+	}
+
+	if scenario.Name != "Initiation Test" || scenario.Names["handler"] != "Alder Mink" {
+		t.Fatalf("unexpected names %+v", scenario.Names)
+	}
+
+	if len(scenario.Codes) != 2 || scenario.Codes["examine"][0] != "cx" || scenario.Codes["handler"][0] != "ec" {
+		t.Fatalf("unexpected codes %+v", scenario.Codes)
+	}
+
+	if len(scenario.Entries) != 4 {
+		t.Fatalf("expected 4 entries, got %+v", scenario.Entries)
+	}
+
+	examine := scenario.Entries[1]
+	expected := `<title>A Rusty Door</title> {name handler} <piece>ec</piece> watches.` + "\n" + `{pull "wasted action"}`
+	if examine.Text != expected || len(examine.Inputs) != 2 || examine.Inputs[1] != "examine" {
+		t.Fatalf("unexpected entry %+v", examine)
+	}
+
+	strong := scenario.Entries[2]
+	if strong.Conditions[0] != "player is not strong" || strong.Text != "Nope." || len(strong.Effects) != 2 ||
+		strong.Effects[0].Action != "retain_turn" || strong.Effects[1].Args[0] != "entrance_door is open" {
+		t.Fatalf("unexpected entry %+v", strong)
+	}
+
+	wasted := scenario.Entries[3]
+	if wasted.Label != "wasted action" || wasted.Text != "<i>(No turn used.)</i>" || wasted.Source != "start.tale" {
+		t.Fatalf("unexpected entry %+v", wasted)
+	}
+}
+
+func TestWriteTaelmoorFixture(t *testing.T) {
+	root, err := filepath.Abs("../../tales/initiation-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := loader.Load(loader.FindNested(root, ".tale"))
+	if errors := loader.Errors(files); len(errors) > 0 {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTaelmoor(&buf, loader.Blocks(files)); err != nil {
+		t.Fatal(err)
+	}
+
+	var scenario taelmoorScenario
+	if err := json.Unmarshal(buf.Bytes(), &scenario); err != nil {
+		t.Fatal(err)
+	}
+
+	if scenario.Name != "Initiation Test" || len(scenario.Codes["examine"]) != 1 ||
+		scenario.Codes["examine"][0] != "cx" || scenario.Codes["entrance_door"][0] != "d1" {
+		t.Fatalf("unexpected scenario %+v %+v", scenario.Name, scenario.Codes)
+	}
+
+	for _, entry := range scenario.Entries {
+		if len(entry.Inputs) == 2 && entry.Inputs[0] == "entrance_door" && entry.Inputs[1] == "examine" {
+			if entry.Source != "entrance.tale" || !strings.HasPrefix(entry.Text, "<title>A Rusty Door</title>") ||
+				!strings.Contains(entry.Text, "<piece>a6</piece>") {
+				t.Fatalf("unexpected entry %+v", entry)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected an entry for examining the entrance door, got %+v", scenario.Entries)
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"tale/blocks"
+	"tale/state"
+	"tale/tokens"
+)
+
+// Enclosed text is passed as a final text input, so {name door}Door{/name}
+// behaves the same as {name door "Door"}
+func (g *Game) actionArgs(node blocks.BodyNode) []blocks.Expression {
+	if node.Type != blocks.ENCLOSING_NODE {
+		return node.Args
+	}
+
+	text := blocks.Expression{Token: tokens.Token{
+		Type: tokens.TEXT,
+		Literal: g.render(node.Children),
+		Line: node.Token.Line,
+		Column: node.Token.Column,
+	}}
+	return append(append([]blocks.Expression{}, node.Args...), text)
+}
+
+func (g *Game) runSet(node blocks.BodyNode) {
+	args := g.actionArgs(node)
+
+	switch {
+	case len(args) == 2:
+		if ref, ok := g.resolve(args[0]); ok {
+			g.set(args[0].Token, ref, g.evaluate(args[1]))
+		}
+
+	case len(args) == 1:
+		g.runSetCondition(args[0])
+
+	default:
+		g.errorf(node.Token, "set expects a variable and optionally a value")
+	}
+}
+
+// Handles the single input forms of set: {set lit}, {set door is locked},
+// {set door is not locked}, {set room is 3}, and {set key in player}
+func (g *Game) runSetCondition(expr blocks.Expression) {
+	negated := expr.Token.Type == tokens.NOT
+	if negated {
+		expr = *expr.Right
+	}
+
+	switch expr.Token.Type {
+	case tokens.IS:
+		if g.isFlagCheck(expr) {
+			if object, ok := g.evaluateObject(*expr.Left); ok {
+				g.set(expr.Token, reference{object: object, key: expr.Right.Token.Literal}, state.Flag(!negated))
+			}
+		} else if negated {
+			g.errorf(expr.Token, "cannot set %s to not a value", expr.Left.Format())
+		} else if ref, ok := g.resolve(*expr.Left); ok {
+			g.set(expr.Token, ref, g.evaluate(*expr.Right))
+		}
+
+	case tokens.IN:
+		if negated {
+			g.errorf(expr.Token, "cannot set an object to not be in another, place it somewhere instead")
+		} else {
+			g.runPlace(expr.Token, *expr.Left, *expr.Right)
+		}
+
+	default:
+		if negated {
+			g.errorf(expr.Token, "use unset to unset %s", expr.Format())
+		} else if ref, ok := g.resolve(expr); ok {
+			g.set(expr.Token, ref, state.Flag(true))
+		}
+	}
+}
+
+func (g *Game) runUnset(node blocks.BodyNode) {
+	if len(node.Args) == 0 {
+		g.errorf(node.Token, "unset expects a variable")
+	}
+
+	for _, arg := range node.Args {
+		if ref, ok := g.resolve(arg); ok {
+			g.unset(ref)
+		}
+	}
+}
+
+func (g *Game) runPlace(token tokens.Token, objectExpr blocks.Expression, locationExpr blocks.Expression) {
+	object, objectOk := g.evaluateObject(objectExpr)
+	location, locationOk := g.evaluateObject(locationExpr)
+
+	if objectOk && locationOk {
+		if err := g.state.Place(object, location); err != nil {
+			g.errorf(token, "%s", err)
+		} else {
+			g.setInitial(token, reference{object: object, key: state.LOCATION}, state.ObjectRef(location))
+		}
+	}
+}
+
+func (g *Game) runName(node blocks.BodyNode) {
+	args := g.actionArgs(node)
+	if len(args) != 2 {
+		g.errorf(node.Token, "name expects an object and its name")
+		return
+	}
+
+	object, ok := g.evaluateObject(args[0])
+	if !ok {
+		return
+	}
+
+	name := g.state.Display(g.evaluate(args[1]))
+	if err := g.state.SetName(object, name); err != nil {
+		g.errorf(node.Token, "%s", err)
+	} else {
+		g.setInitial(node.Token, reference{object: object, key: state.NAME}, state.Text(name))
+	}
+}
+
+// Blocks may "do" each other, so this limits runaway loops
+const maxDoDepth = 100
+
+func (g *Game) runDo(node blocks.BodyNode) string {
+	var aliases []string
+	for _, arg := range node.Args {
+		if arg.Token.Type != tokens.NAME {
+			g.errorf(arg.Token, "do expects the names of aliases")
+			return ""
+		}
+		aliases = append(aliases, arg.Token.Literal)
+	}
+
+	if g.doDepth >= maxDoDepth {
+		g.errorf(node.Token, "do triggered more than %d blocks in a row, is there a loop?", maxDoDepth)
+		return ""
+	}
+
+	c, ok := g.selectBlock(g.blocks, aliasMatcher(aliases))
+	if !ok {
+		return ""
+	}
+
+	g.doDepth++
+	defer func() { g.doDepth-- }()
+	return g.trigger(c)
+}
+
+// Runs an action, returning any text it displays
+func (g *Game) runAction(node blocks.BodyNode) string {
+	switch node.Name {
+	case "set":
+		g.runSet(node)
+	case "unset":
+		g.runUnset(node)
+	case "place":
+		args := node.Args
+		if len(args) != 2 {
+			g.errorf(node.Token, "place expects an object and a location")
+		} else {
+			g.runPlace(node.Token, args[0], args[1])
+		}
+	case "name":
+		g.runName(node)
+	case "alias":
+		// Aliases are collected when the game is created
+	case "do":
+		return g.runDo(node)
+	case "if":
+		if len(node.Args) != 1 {
+			g.errorf(node.Token, "if expects a single condition")
+		} else if g.test(node.Args[0]) {
+			return g.render(node.Children)
+		}
+	case "choose":
+		return g.runChoose(node)
+	case "chain", "chance":
+		return g.runChainOrChance(node)
+	case "choice":
+		g.errorf(node.Token, "choice only works directly inside a choose, chain, or chance action")
+	default:
+		if node.Type == blocks.ENCLOSING_NODE {
+			return g.render(node.Children)
+		}
+	}
+
+	return ""
+}
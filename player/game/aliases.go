@@ -0,0 +1,230 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"tale/blocks"
+	"tale/tokens"
+	"unicode"
+)
+
+// A state header wrapping an alias, with what "it" and "repeat" refer to
+// within that header
+type aliasCondition struct {
+	expr blocks.Expression
+	it string
+	repeatKey string
+}
+
+// An alias action, which only adds its inputs while the state headers
+// wrapping it are valid. It and repeatKey are those of the block the alias
+// is written in.
+type aliasDeclaration struct {
+	path string
+	it string
+	repeatKey string
+	conditions []aliasCondition
+	node blocks.BodyNode
+}
+
+// Returns whether an alias applies to an input
+type matcher func(alias string) bool
+
+var endQuotes = map[rune]rune{
+	'"': '"',
+	'\'': '\'',
+	'`': '`',
+	'“': '”',
+	'”': '”',
+	'‘': '’',
+	'’': '’',
+	'„': '“',
+	'‚': '‘',
+	'«': '»',
+	'‹': '›',
+	'»': '«',
+	'›': '‹',
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// Lowercases text and splits it into words, dropping punctuation
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+// Splits an alias list on spaces, keeping quoted entries together
+func splitAliasList(list string) []string {
+	var entries []string
+	runes := []rune(list)
+
+	for i := 0; i < len(runes); i++ {
+		if unicode.IsSpace(runes[i]) {
+			continue
+		}
+
+		end, quoted := endQuotes[runes[i]]
+		if quoted {
+			i++
+		} else {
+			end = ' '
+		}
+
+		start := i
+		for i < len(runes) && runes[i] != end && !(!quoted && unicode.IsSpace(runes[i])) {
+			i++
+		}
+
+		if entry := string(runes[start:i]); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func containsSequence(words []string, sequence []string) bool {
+	if len(sequence) == 0 {
+		return false
+	}
+
+	for i := 0; i + len(sequence) <= len(words); i++ {
+		if slices.Equal(words[i:i + len(sequence)], sequence) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parent is the block wrapping the blocks, if any
+func (g *Game) collectAliases(taleBlocks []blocks.Block, conditions []aliasCondition, parent *blocks.Block) {
+	parentIt, parentKey := "", ""
+	if parent != nil {
+		parentIt, parentKey = parent.It, g.blockKey(*parent)
+	}
+
+	for i, block := range taleBlocks {
+		blockConditions := conditions
+		if block.Type == blocks.STATE {
+			blockConditions = slices.Clip(conditions)
+			for _, expr := range block.Header {
+				blockConditions = append(blockConditions, aliasCondition{expr: expr, it: parentIt, repeatKey: parentKey})
+			}
+		}
+
+		decl := aliasDeclaration{
+			path: block.Path,
+			it: block.It,
+			repeatKey: g.blockKey(block),
+			conditions: blockConditions,
+		}
+		g.collectBodyAliases(block.Body, decl)
+		g.collectAliases(block.ChildBlocks, blockConditions, &taleBlocks[i])
+	}
+}
+
+func (g *Game) collectBodyAliases(body []blocks.BodyNode, decl aliasDeclaration) {
+	for _, node := range body {
+		if node.Name != "alias" {
+			g.collectBodyAliases(node.Children, decl)
+			continue
+		}
+
+		if len(node.Args) == 0 || node.Args[0].Token.Type != tokens.NAME {
+			g.path = decl.path
+			g.errorf(node.Token, "alias expects the name of an alias followed by its inputs")
+			continue
+		}
+
+		name := node.Args[0].Token.Literal
+		decl.node = node
+		g.aliases[name] = append(g.aliases[name], decl)
+	}
+}
+
+// Tests each condition with "it" and "repeat" referring to what they did
+// in the header the condition came from
+func (g *Game) testAliasConditions(decl aliasDeclaration) bool {
+	for _, condition := range decl.conditions {
+		g.it, g.repeatKey = condition.it, condition.repeatKey
+		if !g.test(condition.expr) {
+			return false
+		}
+	}
+	return true
+}
+
+func collectText(body []blocks.BodyNode) string {
+	var sb strings.Builder
+
+	for _, node := range body {
+		if node.Type == blocks.TEXT_NODE {
+			sb.WriteString(node.Text)
+		}
+		sb.WriteString(collectText(node.Children))
+	}
+
+	return sb.String()
+}
+
+// Every input currently added to an alias. Names always match themselves,
+// with underscores as spaces, and objects also match their name value.
+func (g *Game) aliasEntries(name string) []string {
+	entries := []string{name}
+
+	if strings.Contains(name, "_") {
+		entries = append(entries, strings.ReplaceAll(name, "_", " "))
+	}
+
+	if g.state.IsObject(name) {
+		entries = append(entries, g.state.Name(name))
+	}
+
+	prevPath, prevIt, prevRepeatKey := g.path, g.it, g.repeatKey
+	defer func() { g.path, g.it, g.repeatKey = prevPath, prevIt, prevRepeatKey }()
+
+	for _, decl := range g.aliases[name] {
+		g.path = decl.path
+		if !g.testAliasConditions(decl) {
+			continue
+		}
+
+		g.it, g.repeatKey = decl.it, decl.repeatKey
+
+		for _, arg := range decl.node.Args[1:] {
+			entries = append(entries, splitAliasList(g.state.Display(g.evaluate(arg)))...)
+		}
+
+		if decl.node.Type == blocks.ENCLOSING_NODE {
+			entries = append(entries, splitAliasList(collectText(decl.node.Children))...)
+		}
+	}
+
+	return entries
+}
+
+// Matches aliases with any of their inputs found in the player's text
+func (g *Game) inputMatcher(input string) matcher {
+	words := splitWords(input)
+
+	return func(alias string) bool {
+		for _, entry := range g.aliasEntries(alias) {
+			if containsSequence(words, splitWords(entry)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Matches exactly the named aliases, as used by the "do" action
+func aliasMatcher(aliases []string) matcher {
+	return func(alias string) bool {
+		return slices.Contains(aliases, alias)
+	}
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"slices"
+	"tale/blocks"
+)
+
+// The choice actions directly inside a choose, chain, or chance action
+func choicesOf(node blocks.BodyNode) []blocks.BodyNode {
+	var choices []blocks.BodyNode
+	for _, child := range node.Children {
+		if child.Type == blocks.ENCLOSING_NODE && child.Name == "choice" {
+			choices = append(choices, child)
+		}
+	}
+	return choices
+}
+
+// The indexes of the choices with a valid condition or no condition at all
+func (g *Game) validChoices(choices []blocks.BodyNode) []int {
+	var valid []int
+	for i, choice := range choices {
+		switch {
+		case len(choice.Args) > 1:
+			g.errorf(choice.Token, "choice expects at most one condition")
+		case len(choice.Args) == 0 || g.test(choice.Args[0]):
+			valid = append(valid, i)
+		}
+	}
+	return valid
+}
+
+func (g *Game) runChoose(node blocks.BodyNode) string {
+	choices := choicesOf(node)
+	if valid := g.validChoices(choices); len(valid) > 0 {
+		return g.render(choices[valid[0]].Children)
+	}
+	return ""
+}
+
+// Chain and chance remember which choices each action has displayed, keyed
+// by the action's position, and start over once every valid choice has been.
+// Chain displays the first choice remaining, and chance a random one.
+func (g *Game) runChainOrChance(node blocks.BodyNode) string {
+	choices := choicesOf(node)
+	valid := g.validChoices(choices)
+	if len(valid) == 0 {
+		return ""
+	}
+
+	key := g.siteKey(g.path, node.Token.Line, node.Token.Column)
+	displayed := g.choices[key]
+
+	remaining := slices.DeleteFunc(slices.Clone(valid), func(i int) bool {
+		return slices.Contains(displayed, i)
+	})
+	if len(remaining) == 0 {
+		displayed, remaining = nil, valid
+	}
+
+	next := remaining[0]
+	if node.Name == "chance" {
+		next = remaining[g.random.IntN(len(remaining))]
+	}
+
+	g.choices[key] = append(displayed, next)
+	return g.render(choices[next].Children)
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"tale/blocks"
+	"tale/state"
+	"tale/tokens"
+)
+
+// "door is locked" is a flag of an object when door is an object or "it"
+// and locked is not an object, while "score is 3" and "location of player
+// is cell" are values. The check package uses the same rule for objects
+// named directly.
+func (g *Game) isFlagCheck(expr blocks.Expression) bool {
+	left := *expr.Left
+	return expr.Token.Type == tokens.IS &&
+		(left.Token.Type == tokens.IT || blocks.IsBareName(left) && g.state.IsObject(left.Token.Literal)) &&
+		blocks.IsBareName(*expr.Right) &&
+		!g.state.IsObject(expr.Right.Token.Literal)
+}
+
+func (g *Game) testIs(expr blocks.Expression) bool {
+	if g.isFlagCheck(expr) {
+		object := g.evaluate(*expr.Left).Object
+		return g.state.GetValue(object, expr.Right.Token.Literal).IsSet()
+	}
+	return g.evaluate(*expr.Left).Equals(g.evaluate(*expr.Right))
+}
+
+func (g *Game) testLocation(expr blocks.Expression) bool {
+	left, leftOk := g.evaluateObject(*expr.Left)
+	right, rightOk := g.evaluateObject(*expr.Right)
+	if !leftOk || !rightOk {
+		return false
+	}
+
+	switch expr.Token.Type {
+	case tokens.IN:
+		return g.state.Location(left) == right
+	case tokens.HAS:
+		return g.state.Location(right) == left
+	default:
+		location := g.state.Location(left)
+		return location != "" && location == g.state.Location(right)
+	}
+}
+
+// Tests a condition against the current state, as a state header would
+func (g *Game) Test(condition blocks.Expression) bool {
+	return g.test(condition)
+}
+
+// Whether a condition in a state header, "if", or "choice" is valid. An
+// object on its own, like "= cell =" or "= it =", means the player is in
+// the object.
+func (g *Game) test(expr blocks.Expression) bool {
+	switch expr.Token.Type {
+	case tokens.AND:
+		return g.test(*expr.Left) && g.test(*expr.Right)
+	case tokens.OR:
+		return g.test(*expr.Left) || g.test(*expr.Right)
+	case tokens.NOT:
+		return !g.test(*expr.Right)
+	case tokens.IS:
+		return g.testIs(expr)
+	case tokens.HAS, tokens.IN, tokens.WITH:
+		return g.testLocation(expr)
+	case tokens.NAME:
+		if expr.Left == nil && expr.Right == nil && g.state.IsObject(expr.Token.Literal) {
+			return g.state.Location(state.PLAYER) == expr.Token.Literal
+		}
+		return g.evaluate(expr).IsSet()
+	case tokens.IT:
+		value := g.evaluate(expr)
+		return value.Type == state.OBJECT && g.state.Location(state.PLAYER) == value.Object
+	default:
+		return g.evaluate(expr).IsSet()
+	}
+}
+
+// All conditions must be valid, and an empty list of conditions is valid
+func (g *Game) testAll(conditions []blocks.Expression) bool {
+	for _, condition := range conditions {
+		if !g.test(condition) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"fmt"
+	"tale/blocks"
+	"tale/state"
+	"tale/tokens"
+)
+
+// A variable, or a value of an object when object is not empty
+type reference struct {
+	object string
+	key string
+}
+
+func (r reference) String() string {
+	if r.object == "" {
+		return r.key
+	}
+	return r.key + " of " + r.object
+}
+
+func (g *Game) evaluateObject(expr blocks.Expression) (string, bool) {
+	value := g.evaluate(expr)
+	if value.Type != state.OBJECT {
+		g.errorf(expr.Token, "%s is not an object", expr.Format())
+		return "", false
+	}
+	return value.Object, true
+}
+
+func (g *Game) resolve(expr blocks.Expression) (reference, bool) {
+	switch expr.Token.Type {
+	case tokens.NAME:
+		return reference{key: expr.Token.Literal}, true
+
+	case tokens.OF:
+		object, ok := g.evaluateObject(*expr.Right)
+		return reference{object: object, key: expr.Left.Token.Literal}, ok
+
+	case tokens.COLON:
+		object, ok := g.evaluateObject(*expr.Left)
+		return reference{object: object, key: expr.Right.Token.Literal}, ok
+
+	default:
+		g.errorf(expr.Token, "%s is not a variable or a value of an object", expr.Format())
+		return reference{}, false
+	}
+}
+
+func (g *Game) get(ref reference) state.Value {
+	if ref.object == "" && state.IsRepeat(ref.key) {
+		return g.getRepeat(ref.key)
+	}
+	if ref.object == "" {
+		return g.state.Get(ref.key)
+	}
+	return g.state.GetValue(ref.object, ref.key)
+}
+
+func (g *Game) set(token tokens.Token, ref reference, value state.Value) {
+	var err error
+
+	if ref.object == "" && state.IsRepeat(ref.key) {
+		err = fmt.Errorf("cannot set %s, it is set automatically when a block repeats", ref.key)
+	} else if ref.object == "" {
+		err = g.state.Set(ref.key, value)
+	} else {
+		err = g.state.SetValue(ref.object, ref.key, value)
+	}
+
+	if err != nil {
+		g.errorf(token, "%s", err)
+	} else {
+		g.setInitial(token, ref, value)
+	}
+}
+
+func (g *Game) unset(ref reference) {
+	if ref.object == "" {
+		g.state.Unset(ref.key)
+	} else {
+		g.state.UnsetValue(ref.object, ref.key)
+	}
+}
+
+func (g *Game) evaluateNumber(expr blocks.Expression) (state.Num, bool) {
+	value := g.evaluate(expr)
+
+	switch value.Type {
+	case state.NUMBER, state.UNSET:
+		return value.Number, true
+	default:
+		g.errorf(expr.Token, "%s is not a number", expr.Format())
+		return state.Num{}, false
+	}
+}
+
+func (g *Game) evaluateArithmetic(expr blocks.Expression) state.Value {
+	if expr.Left == nil {
+		right, ok := g.evaluateNumber(*expr.Right)
+		if !ok {
+			return state.Value{}
+		}
+		return state.Number(right.Neg())
+	}
+
+	left, leftOk := g.evaluateNumber(*expr.Left)
+	right, rightOk := g.evaluateNumber(*expr.Right)
+	if !leftOk || !rightOk {
+		return state.Value{}
+	}
+
+	var result state.Num
+	var err error
+
+	switch expr.Token.Type {
+	case tokens.PLUS:
+		result = left.Add(right)
+	case tokens.MINUS:
+		result = left.Sub(right)
+	case tokens.MULTIPLY:
+		result = left.Mul(right)
+	case tokens.DIVIDE:
+		result, err = left.Div(right)
+	case tokens.REMAINDER:
+		result, err = left.Rem(right)
+	case tokens.GT:
+		return state.Flag(left.Cmp(right) > 0)
+	case tokens.LT:
+		return state.Flag(left.Cmp(right) < 0)
+	case tokens.GTE:
+		return state.Flag(left.Cmp(right) >= 0)
+	case tokens.LTE:
+		return state.Flag(left.Cmp(right) <= 0)
+	default:
+		return state.Value{}
+	}
+
+	if err != nil {
+		g.errorf(expr.Token, "%s in %s", err, expr.Format())
+		return state.Value{}
+	}
+	return state.Number(result)
+}
+
+func (g *Game) evaluate(expr blocks.Expression) state.Value {
+	switch expr.Token.Type {
+	case tokens.NUMBER:
+		number, err := state.ParseNum(expr.Token.Literal)
+		if err != nil {
+			g.errorf(expr.Token, "%s", err)
+			return state.Value{}
+		}
+		return state.Number(number)
+
+	case tokens.TEXT:
+		return state.Text(expr.Token.Literal)
+
+	case tokens.FLAG:
+		return state.Flag(state.ParseFlag(expr.Token.Literal))
+
+	case tokens.IT:
+		if g.it == "" {
+			g.errorf(expr.Token, "it does not refer to any object here")
+			return state.Value{}
+		}
+		return state.ObjectRef(g.it)
+
+	case tokens.NAME, tokens.OF, tokens.COLON:
+		ref, ok := g.resolve(expr)
+		if !ok {
+			return state.Value{}
+		}
+		return g.get(ref)
+
+	case tokens.AND, tokens.OR, tokens.NOT, tokens.IS, tokens.HAS, tokens.IN, tokens.WITH:
+		return state.Flag(g.test(expr))
+
+	case tokens.PLUS, tokens.MINUS, tokens.MULTIPLY, tokens.DIVIDE, tokens.REMAINDER,
+		tokens.GT, tokens.LT, tokens.GTE, tokens.LTE:
+		return g.evaluateArithmetic(expr)
+
+	default:
+		g.errorf(expr.Token, "cannot evaluate %s", expr.Format())
+		return state.Value{}
+	}
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"math/rand/v2"
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/state"
+	"tale/tokens"
+)
+
+type Game struct {
+	blocks []blocks.Block
+	state *state.State
+	aliases map[string][]aliasDeclaration
+	path string
+	it string
+	root string
+	triggers map[string]int
+	repeatKey string
+	choices map[string][]int
+	seed uint64
+	source *rand.PCG
+	random *rand.Rand
+	doDepth int
+	started bool
+	initial map[reference]initialValue
+	errors []diagnostics.Diagnostic
+}
+
+// A value set by a start block, kept while the game starts in order to
+// catch start blocks in different files which disagree
+type initialValue struct {
+	value state.Value
+	path string
+	token tokens.Token
+}
+
+// The seed is the only source of randomness in a game, so a game given the
+// same seed and inputs always plays out the same way
+func New(taleBlocks []blocks.Block, seed uint64) *Game {
+	source := rand.NewPCG(seed, 0)
+	g := &Game{
+		blocks: taleBlocks,
+		state: state.New(),
+		aliases: make(map[string][]aliasDeclaration),
+		root: blocks.CommonDir(taleBlocks),
+		triggers: make(map[string]int),
+		choices: make(map[string][]int),
+		seed: seed,
+		source: source,
+		random: rand.New(source),
+	}
+
+	blocks.ResolveIt(g.blocks)
+	g.addObjects()
+	g.collectAliases(g.blocks, nil, nil)
+	return g
+}
+
+// Every name used as an object in the tale is an object from the start,
+// even before it has any values
+func (g *Game) addObjects() {
+	for _, object := range blocks.FindObjects(g.blocks) {
+		g.state.AddObject(object)
+	}
+}
+
+func (g *Game) Seed() uint64 {
+	return g.seed
+}
+
+func (g *Game) errorf(token tokens.Token, format string, args ...any) {
+	g.errors = append(g.errors, diagnostics.New(g.path, token, format, args...))
+}
+
+func (g *Game) warnf(token tokens.Token, format string, args ...any) {
+	g.errors = append(g.errors, diagnostics.NewWarning(g.path, token, format, args...))
+}
+
+// Returns the runtime errors and warnings since the last call
+func (g *Game) Errors() []diagnostics.Diagnostic {
+	errors := g.errors
+	g.errors = nil
+	return errors
+}
+
+func (g *Game) State() *state.State {
+	return g.state
+}
+
+// Runs the start block of every file in load order, returning their text
+// separated by empty lines. See the loader package for the load order.
+func (g *Game) Start() string {
+	if g.started {
+		return ""
+	}
+	g.started = true
+
+	g.initial = make(map[reference]initialValue)
+	defer func() { g.initial = nil }()
+
+	var texts []string
+
+	for _, block := range g.blocks {
+		if block.Type == blocks.START {
+			texts = appendText(texts, g.run(block))
+		}
+	}
+
+	return strings.Join(texts, "\n\n")
+}
+
+// Warns when start blocks in different files set the same variable or
+// object value to different values. The last file in load order wins.
+func (g *Game) setInitial(token tokens.Token, ref reference, value state.Value) {
+	if g.initial == nil {
+		return
+	}
+
+	prev, ok := g.initial[ref]
+	if ok && prev.path != g.path && !prev.value.Equals(value) {
+		g.warnf(
+			token,
+			"%s is set to %s here, but the start block at %s set it to %s, start blocks in different files should not disagree",
+			ref, g.state.Display(value), g.siteKey(prev.path, prev.token.Line, prev.token.Column), g.state.Display(prev.value),
+		)
+	}
+
+	g.initial[ref] = initialValue{value: value, path: g.path, token: token}
+}
+
+// Triggers the block matching the player's input, returning the text to
+// display and whether any block matched
+func (g *Game) Input(input string) (string, bool) {
+	c, ok := g.selectBlock(g.blocks, g.inputMatcher(input))
+	if !ok {
+		return "", false
+	}
+
+	return g.trigger(c), true
+}
+
+func (g *Game) run(block blocks.Block) string {
+	prevPath, prevIt, prevRepeatKey := g.path, g.it, g.repeatKey
+	g.path, g.it, g.repeatKey = block.Path, block.It, g.blockKey(block)
+	defer func() { g.path, g.it, g.repeatKey = prevPath, prevIt, prevRepeatKey }()
+
+	return strings.TrimSpace(g.render(block.Body))
+}
+
+func appendText(texts []string, text string) []string {
+	if text == "" {
+		return texts
+	}
+	return append(texts, text)
+}
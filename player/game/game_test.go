@@ -0,0 +1,528 @@
+package game
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"tale/parser"
+	"tale/state"
+	"testing"
+)
+
+func newTestGame(t *testing.T, sources ...string) *Game {
+	t.Helper()
+	return New(parser.MustParseFiles(t, t.TempDir(), sources...), 1)
+}
+
+func expectStart(t *testing.T, g *Game, expected string) {
+	t.Helper()
+
+	if actual := g.Start(); actual != expected {
+		t.Fatalf("start: expected=%q, got=%q", expected, actual)
+	}
+
+	if errors := g.Errors(); len(errors) > 0 {
+		t.Fatalf("start: unexpected errors: %v", errors)
+	}
+}
+
+func expectInput(t *testing.T, g *Game, input string, expected string) {
+	t.Helper()
+
+	actual, ok := g.Input(input)
+	if !ok {
+		actual = "(nothing happens)"
+	}
+
+	if actual != expected {
+		t.Fatalf("%q: expected=%q, got=%q", input, expected, actual)
+	}
+
+	if errors := g.Errors(); len(errors) > 0 {
+		t.Fatalf("%q: unexpected errors: %v", input, errors)
+	}
+}
+
+func TestActions(t *testing.T) {
+	g := newTestGame(t, `{name player "Alice"}{set score 3}{set door is locked}
+{name door}Iron Door{/name}{place player cell}
+{set score score * 2 + 1}{set lit}
+Hello {player}, {score} points. {name of door} {door:locked} {location of player}`)
+
+	expectStart(t, g, "Hello Alice, 7 points. Iron Door yes cell")
+}
+
+// The example of "is" from the overview, where door is only ever used
+// with "is" and locked is never set as a value of its own
+func TestObjectFlags(t *testing.T) {
+	g := newTestGame(t, `> key >
+
+== door is locked ==
+{set door is not locked}
+You unlocked it!
+
+== door is not locked ==
+{set door is locked}
+You locked it!`)
+
+	expectStart(t, g, "")
+	expectInput(t, g, "key", "You locked it!")
+	expectInput(t, g, "key", "You unlocked it!")
+	expectInput(t, g, "key", "You locked it!")
+}
+
+func TestComparingVariables(t *testing.T) {
+	g := newTestGame(t, `{set score 1}{set goal 1}
+
+> check >
+== score is goal ==
+Goal reached!
+
+> score >
+{set score score + 1}Scored.`)
+
+	expectStart(t, g, "")
+	expectInput(t, g, "check", "Goal reached!")
+	expectInput(t, g, "score", "Scored.")
+	expectInput(t, g, "check", "")
+}
+
+func TestArithmetic(t *testing.T) {
+	g := newTestGame(t, `{set debt -1,000.5}{set big 1_000_000_000}{set share 10 / 4}
+{debt}, {big * 3}, {share}, {0.1 + 0.2}, {1 / 3}, {-7 % 2}, {7 / 7 is 1}, {share > 2.5}
+
+> divide >
+{set share 1 / 0}{1 % 0}Divided`)
+
+	expectStart(t, g, "-1000.5, 3000000000, 2.5, 0.3, 0.3333333333, -1, yes, no")
+
+	text, _ := g.Input("divide")
+	if text != "Divided" {
+		t.Fatalf("expected=%q, got=%q", "Divided", text)
+	}
+
+	errors := g.Errors()
+	if len(errors) != 2 ||
+		errors[0].Message != "cannot divide by zero in 1 / 0" ||
+		errors[1].Message != "cannot divide by zero in 1 % 0" {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+
+	g = newTestGame(t, `{set score 1}{name of (score + 1)}{-(score is 1)}{set (score + 1) 2}`)
+	g.Start()
+	errors = g.Errors()
+	if len(errors) != 3 ||
+		errors[0].Message != "score + 1 is not an object" ||
+		errors[1].Message != "score is 1 is not a number" ||
+		errors[2].Message != "score + 1 is not a variable or a value of an object" {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	g := newTestGame(t, `{place player cell}{place chest cell}{place key chest}{place cell dungeon}
+
+> look >
+== cell ==
+{if key in chest}In the chest.{/if}{if key in cell}On the floor.{/if}{if player has key}You hold the key.{/if}{if chest with player}The chest is here.{/if}{if key with player}The key is nearby.{/if}
+
+== dining_room ==
+Dinner is served.
+
+> take >
+{place key player}Taken.
+
+> leave >
+{place player dining_room}You leave.
+
+> hide >
+{place dungeon chest}Hidden.
+
+> teleport >
+{set location of player is dungeon}At {location of player}.`)
+
+	expectStart(t, g, "")
+	expectInput(t, g, "look", "In the chest.The chest is here.")
+	expectInput(t, g, "take", "Taken.")
+	expectInput(t, g, "look", "You hold the key.The chest is here.")
+
+	text, _ := g.Input("hide")
+	errors := g.Errors()
+	if text != "Hidden." || len(errors) != 1 || errors[0].Message != "cannot place dungeon in chest, chest is already inside dungeon" {
+		t.Fatalf("unexpected text %q and errors %v", text, errors)
+	}
+
+	expectInput(t, g, "leave", "You leave.")
+	expectInput(t, g, "look", "Dinner is served.")
+	expectInput(t, g, "teleport", "At dungeon.")
+
+	if g.State().GetValue("dining_room", "dungeon").IsSet() {
+		t.Fatalf("expected the player to be moved rather than a flag set on the room")
+	}
+}
+
+func TestIt(t *testing.T) {
+	g := newTestGame(t, `{place player cell}{name cell "the cell"}{place lamp cell}
+{set cell is open}
+
+> leave >
+== player in cell ==
+You look at the door of {it}.{do look}{set it is open}
+
+=== it is open ===
+{alias leave}exit{/alias}You peek out the door of {it}.{set it is not open}
+
+> look >
+== lamp with player ==
+You see {it}.
+
+> enter >
+>> lamp >>
+=== it ===
+You can't fit in {it}.`)
+
+	expectStart(t, g, "")
+	expectInput(t, g, "leave", "You peek out the door of the cell.")
+	expectInput(t, g, "leave", "You look at the door of the cell.You see lamp.")
+	expectInput(t, g, "leave", "You peek out the door of the cell.")
+	expectInput(t, g, "exit", "(nothing happens)")
+	expectInput(t, g, "leave", "You look at the door of the cell.You see lamp.")
+	expectInput(t, g, "exit", "You peek out the door of the cell.")
+	expectInput(t, g, "enter lamp", "")
+
+	g.State().Place(state.PLAYER, "lamp")
+	expectInput(t, g, "enter lamp", "You can't fit in lamp.")
+
+	g = newTestGame(t, "{it}")
+	g.Start()
+	if errors := g.Errors(); len(errors) != 1 || errors[0].Message != "it does not refer to any object here" {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	g := newTestGame(t, `
+> greet >
+Hello.{if repeat} Again.{/if}
+
+== repeat ==
+Hello again, {repeats + 2} times now.{if repeats > 1} Enough!{/if}
+
+> wave >
+{do greet}
+
+> cheat >
+{set repeat}`)
+
+	expectInput(t, g, "greet", "Hello.")
+	expectInput(t, g, "greet", "Hello again, 2 times now.")
+	expectInput(t, g, "wave", "Hello again, 3 times now.")
+	expectInput(t, g, "greet", "Hello again, 4 times now. Enough!")
+	expectInput(t, g, "wave", "Hello again, 5 times now. Enough!")
+
+	g.Input("cheat")
+	if errors := g.Errors(); len(errors) != 1 || errors[0].Message != "cannot set repeat, it is set automatically when a block repeats" {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	source := `
+> hit >
+{choose}
+{choice player is strong}You bust through!{/choice}
+{choice}It holds.{/choice}
+{/choose}
+
+> greet >
+{chain}
+{choice player is strong}Hey, big fella{/choice}
+{choice}Hey{/choice}
+{choice}Howdy{/choice}
+{/chain}
+
+> roll >
+{chance}{choice}1{/choice}{choice}2{/choice}{choice}3{/choice}{/chance}
+
+> train >
+{set strong of player}`
+
+	g := newTestGame(t, source)
+	expectInput(t, g, "hit", "It holds.")
+	expectInput(t, g, "greet", "Hey")
+	expectInput(t, g, "greet", "Howdy")
+	expectInput(t, g, "greet", "Hey")
+	expectInput(t, g, "train", "")
+	expectInput(t, g, "hit", "You bust through!")
+	expectInput(t, g, "greet", "Hey, big fella")
+	expectInput(t, g, "greet", "Howdy")
+
+	rolls := make(map[string]bool)
+	for range 3 {
+		text, _ := g.Input("roll")
+		rolls[text] = true
+	}
+	if len(rolls) != 3 {
+		t.Fatalf("expected every roll before any repeats, got %v", rolls)
+	}
+
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newTestGame(t, source)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expectInput(t, loaded, "greet", "Hey, big fella")
+	expectInput(t, loaded, "greet", "Hey")
+
+	if loaded.Seed() != g.Seed() {
+		t.Fatalf("expected seed %d, got %d", g.Seed(), loaded.Seed())
+	}
+	for range 6 {
+		a, _ := g.Input("roll")
+		b, _ := loaded.Input("roll")
+		if a != b {
+			t.Fatalf("expected a loaded game to roll the same as the saved one, got %q and %q", b, a)
+		}
+	}
+
+	first, second := newTestGame(t, source), newTestGame(t, source)
+	for range 6 {
+		a, _ := first.Input("roll")
+		b, _ := second.Input("roll")
+		if a != b {
+			t.Fatalf("expected games with the same seed to roll the same, got %q and %q", a, b)
+		}
+	}
+}
+
+func TestNestedFallbacks(t *testing.T) {
+	g := newTestGame(t, `
+= room is dark =
+It's too dark!
+
+>> light >>
+You can't find the switch.
+
+>>> torch >>>
+{unset room:dark}
+The torch blazes to life.
+
+> light >
+It's already light.
+
+>> torch >>
+You wave the torch around.
+
+> look >
+{if room is not dark}You see a room.{/if}
+`, `{set room is dark}`)
+
+	expectStart(t, g, "")
+	expectInput(t, g, "look", "")
+	expectInput(t, g, "light", "You can't find the switch.")
+	expectInput(t, g, "jump", "(nothing happens)")
+	expectInput(t, g, "light torch", "The torch blazes to life.")
+	expectInput(t, g, "light torch", "You wave the torch around.")
+	expectInput(t, g, "light", "It's already light.")
+	expectInput(t, g, "look", "You see a room.")
+}
+
+func TestTieBreaking(t *testing.T) {
+	g := newTestGame(t, `
+> any >
+Huh?
+
+> greet >
+Hello!
+
+> greet >
+Hi!
+
+> greet dismiss >
+Make up your mind.
+`, `
+> greet >
+== polite ==
+Good day!
+
+> any >
+== polite ==
+Pardon me?
+`)
+
+	expectInput(t, g, "greet", "Hello!")
+	expectInput(t, g, "greet dismiss", "Make up your mind.")
+	expectInput(t, g, "jump", "Huh?")
+
+	g.state.Set("polite", state.Flag(true))
+	expectInput(t, g, "greet", "Good day!")
+	expectInput(t, g, "jump", "Pardon me?")
+	expectInput(t, g, "greet dismiss", "Make up your mind.")
+}
+
+func TestAliases(t *testing.T) {
+	g := newTestGame(t, `{alias greet "wave 'say hello' “tip hat”"}
+{alias insult}yell "cast aspersions"{/alias}
+{alias bounce}jump{/alias}
+{name door}The Iron Door{/name}
+{alias door}hatch{/alias}
+
+= player in bounce_house =
+{alias bounce}step twitch{/alias}
+
+> greet >
+Hail!
+
+> insult >
+How rude.
+
+> bounce >
+Boing.
+
+> knock >
+>> door >>
+Knock knock.
+
+> any >
+Huh?
+
+> enter >
+{place player bounce_house}
+Wheee!
+`)
+
+	expectInput(t, g, "GREET", "Hail!")
+	expectInput(t, g, "I wave.", "Hail!")
+	expectInput(t, g, "say hello", "Hail!")
+	expectInput(t, g, "say", "Huh?")
+	expectInput(t, g, "Tip hat", "Hail!")
+	expectInput(t, g, "cast aspersions", "How rude.")
+	expectInput(t, g, "jump", "Boing.")
+	expectInput(t, g, "step", "Huh?")
+	expectInput(t, g, "knock on the hatch", "Knock knock.")
+	expectInput(t, g, "knock on the iron door", "Knock knock.")
+	expectInput(t, g, "enter", "Wheee!")
+	expectInput(t, g, "step", "Boing.")
+}
+
+func TestAnyInWrappingBlock(t *testing.T) {
+	g := newTestGame(t, `
+> open >
+>> door >>
+It opens.
+
+>> any >>
+Open what?
+
+> any >
+What?
+`)
+
+	expectInput(t, g, "open door", "It opens.")
+	expectInput(t, g, "open window", "Open what?")
+	expectInput(t, g, "close door", "What?")
+}
+
+func TestDo(t *testing.T) {
+	g := newTestGame(t, `
+> run >
+You run.
+
+== fast ==
+You run fast!
+
+> jump >
+{set fast}You jump. {do run}
+
+> loop >
+{do loop}
+`)
+
+	expectInput(t, g, "run", "You run.")
+	expectInput(t, g, "jump", "You jump. You run fast!")
+
+	g.Input("loop")
+	if errors := g.Errors(); len(errors) != 1 {
+		t.Fatalf("expected an error for a do loop, got %v", errors)
+	}
+}
+
+func TestStartBlocks(t *testing.T) {
+	g := newTestGame(t,
+		"Welcome!\n{set score 1}\n{set score 2}\n{place lamp player}",
+		"{set score 2}",
+		"Willkommen!\n{set lives 3}\n\n> score >\nScore {score}, lives {lives}",
+	)
+	expectStart(t, g, "Welcome!\n\nWillkommen!")
+	expectStart(t, g, "")
+	expectInput(t, g, "score", "Score 2, lives 3")
+
+	g = newTestGame(t,
+		"{set score 1}\n{place lamp player}",
+		"{set score 3}\n{place lamp cellar}\n{set score 3}",
+	)
+	g.Start()
+
+	errors := g.Errors()
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 warnings, got %d: %v", len(errors), errors)
+	}
+
+	expected := []string{
+		"2.tale:1:6: warning: score is set to 3 here, but the start block at 1.tale:1:6 set it to 1, start blocks in different files should not disagree",
+		"2.tale:2:1: warning: location of lamp is set to cellar here, but the start block at 1.tale:2:1 set it to player, start blocks in different files should not disagree",
+	}
+	for i, err := range errors {
+		if got := path.Base(err.Path) + strings.TrimPrefix(err.Error(), err.Path); got != expected[i] {
+			t.Fatalf("expected=%q, got=%q", expected[i], got)
+		}
+	}
+
+	if location := g.State().Location("lamp"); location != "cellar" {
+		t.Fatalf("expected the last start block to win, got %q", location)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	source := "Welcome!\n{set score 1}\n{place lamp player}\n\n> score >\n{set score score + 1}Score {score}\n== repeat ==\n{set score score + 1}Score {score} again\n\n> drop >\n{place lamp cellar}Dropped."
+
+	g := newTestGame(t, source)
+	expectStart(t, g, "Welcome!")
+	expectInput(t, g, "score", "Score 2")
+	expectInput(t, g, "drop", "Dropped.")
+
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.String()
+	if !strings.Contains(saved, `"1.tale:5:1": 1`) {
+		t.Fatalf("expected the score block to be saved as triggered once, got %s", saved)
+	}
+
+	loaded := newTestGame(t, source)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStart(t, loaded, "")
+	expectInput(t, loaded, "score", "Score 3 again")
+	if location := loaded.State().Location("lamp"); location != "cellar" {
+		t.Fatalf("expected lamp in cellar, got %q", location)
+	}
+
+	invalid := []string{
+		strings.Replace(saved, `"version": 1`, `"version": 99`, 1),
+		strings.Replace(saved, SAVE_FORMAT, "tale-maker", 1),
+		"not json",
+	}
+	for _, data := range invalid {
+		if err := newTestGame(t, source).Load(strings.NewReader(data)); err == nil {
+			t.Fatalf("expected an error loading %s", data)
+		}
+	}
+}
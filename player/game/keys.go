@@ -0,0 +1,17 @@
+package game
+
+import (
+	"fmt"
+	"tale/blocks"
+)
+
+// Identifies a block or action by its file and position, such as
+// "rooms/cell.tale:12:1"
+func (g *Game) siteKey(path string, line int, column int) string {
+	return fmt.Sprintf("%s:%d:%d", blocks.RelativePath(g.root, path), line, column)
+}
+
+// Blocks are identified by the position of their header
+func (g *Game) blockKey(block blocks.Block) string {
+	return g.siteKey(block.Path, block.Line, block.Column)
+}
@@ -0,0 +1,25 @@
+package game
+
+import (
+	"strings"
+	"tale/blocks"
+)
+
+func (g *Game) render(body []blocks.BodyNode) string {
+	var sb strings.Builder
+
+	for _, node := range body {
+		switch node.Type {
+		case blocks.TEXT_NODE:
+			sb.WriteString(node.Text)
+		case blocks.INTERPOLATION_NODE:
+			for _, arg := range node.Args {
+				sb.WriteString(g.state.Display(g.evaluate(arg)))
+			}
+		case blocks.ACTION_NODE, blocks.ENCLOSING_NODE:
+			sb.WriteString(g.runAction(node))
+		}
+	}
+
+	return sb.String()
+}
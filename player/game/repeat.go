@@ -0,0 +1,25 @@
+package game
+
+import (
+	"tale/state"
+)
+
+// "repeat" is set when the block wrapping it has been triggered before, and
+// "repeats" is the number of times it has been
+func (g *Game) getRepeat(name string) state.Value {
+	count := g.triggers[g.repeatKey]
+	if name == state.REPEAT {
+		return state.Flag(count > 0)
+	}
+	return state.Number(state.Whole(int64(count)))
+}
+
+// Runs a selected block, then counts it and every block wrapping it as
+// triggered, so they repeat from then on
+func (g *Game) trigger(c candidate) string {
+	text := g.run(c.block)
+	for _, key := range c.keys {
+		g.triggers[key]++
+	}
+	return text
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"tale/state"
+)
+
+const (
+	SAVE_FORMAT = "tale-maker-save"
+	SAVE_VERSION = 1
+)
+
+// Aliases are not saved, since they are declared by the tale's files
+// rather than changed as the game is played. Triggers counts how many
+// times each block has been triggered, keyed by its block key, and Choices
+// lists the choices each chain or chance action has displayed. Random is
+// the state of the random source, so a loaded game continues exactly as
+// the saved one would have.
+type saveFile struct {
+	Format string `json:"format"`
+	Version int `json:"version"`
+	Seed uint64 `json:"seed"`
+	Random []byte `json:"random"`
+	State *state.State `json:"state"`
+	Triggers map[string]int `json:"triggers"`
+	Choices map[string][]int `json:"choices"`
+}
+
+// Writes the state of a game in progress as JSON
+func (g *Game) Save(w io.Writer) error {
+	random, err := g.source.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(saveFile{
+		Format: SAVE_FORMAT,
+		Version: SAVE_VERSION,
+		Seed: g.seed,
+		Random: random,
+		State: g.state,
+		Triggers: g.triggers,
+		Choices: g.choices,
+	})
+}
+
+// Replaces the state of a game with a saved one. The game counts as
+// started, so its start blocks will not run again.
+func (g *Game) Load(r io.Reader) error {
+	save := saveFile{State: state.New()}
+	if err := json.NewDecoder(r).Decode(&save); err != nil {
+		return fmt.Errorf("could not read save: %w", err)
+	}
+
+	if save.Format != SAVE_FORMAT {
+		return fmt.Errorf("not a save file, expected format %q but found %q", SAVE_FORMAT, save.Format)
+	}
+	if save.Version != SAVE_VERSION {
+		return fmt.Errorf("unsupported save version %d, expected %d", save.Version, SAVE_VERSION)
+	}
+
+	source := rand.NewPCG(save.Seed, 0)
+	if save.Random != nil {
+		if err := source.UnmarshalBinary(save.Random); err != nil {
+			return fmt.Errorf("could not read save: %w", err)
+		}
+	}
+
+	if save.Triggers == nil {
+		save.Triggers = make(map[string]int)
+	}
+	if save.Choices == nil {
+		save.Choices = make(map[string][]int)
+	}
+
+	g.seed, g.source, g.random = save.Seed, source, rand.New(source)
+	g.state = save.State
+	g.triggers = save.Triggers
+	g.choices = save.Choices
+	g.addObjects()
+	g.started = true
+	return nil
+}
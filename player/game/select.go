@@ -0,0 +1,154 @@
+package game
+
+import (
+	"slices"
+	"tale/blocks"
+	"tale/tokens"
+)
+
+// A block which could be triggered by an input, ranked by how specifically
+// it matched. See selectBlock for how candidates are compared. Keys holds
+// the block keys of every wrapping block, then the block itself.
+type candidate struct {
+	block blocks.Block
+	keys []string
+	matched bool
+	inputs int
+	depth int
+}
+
+func (c candidate) key() string {
+	if len(c.keys) == 0 {
+		return ""
+	}
+	return c.keys[len(c.keys) - 1]
+}
+
+func (c candidate) outranks(other candidate) bool {
+	if c.inputs != other.inputs {
+		return c.inputs > other.inputs
+	}
+	return c.depth > other.depth
+}
+
+func usesAny(block blocks.Block) bool {
+	for _, expr := range block.Header {
+		if expr.Token.Type == tokens.NAME && expr.Token.Literal == "any" {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns whether an input block's header matches, and how many aliases
+// it matched explicitly rather than with "any"
+func matchInput(block blocks.Block, match matcher) (bool, int) {
+	inputs := 0
+
+	for _, expr := range block.Header {
+		if expr.Token.Type != tokens.NAME {
+			return false, 0
+		}
+
+		name := expr.Token.Literal
+		switch {
+		case name == "any":
+		case match(name):
+			inputs++
+		default:
+			return false, 0
+		}
+	}
+
+	return true, inputs
+}
+
+// "any" only matches inputs which match no other input block wrapped by
+// the same block, so it is checked against each sibling first
+func matchesSibling(siblings []blocks.Block, match matcher) bool {
+	for _, block := range siblings {
+		if block.Type == blocks.INPUT && !usesAny(block) {
+			if ok, _ := matchInput(block, match); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Walks the block trees depth first, adding a candidate for each block
+// whose conditions and all its ancestors' conditions are valid. Only blocks
+// within at least one matching input block are candidates, since state
+// blocks alone are not triggered by an input.
+func (g *Game) findCandidates(
+	taleBlocks []blocks.Block,
+	match matcher,
+	parent candidate,
+	candidates []candidate,
+) []candidate {
+	siblingMatched := matchesSibling(taleBlocks, match)
+
+	for _, block := range taleBlocks {
+		next := candidate{
+			block: block,
+			keys: append(slices.Clip(parent.keys), g.blockKey(block)),
+			matched: parent.matched,
+			inputs: parent.inputs,
+			depth: parent.depth + 1,
+		}
+
+		switch block.Type {
+		case blocks.INPUT:
+			ok, inputs := matchInput(block, match)
+			if !ok || (siblingMatched && usesAny(block)) {
+				continue
+			}
+			next.inputs += inputs
+			next.matched = true
+
+		case blocks.STATE:
+			g.path, g.it, g.repeatKey = block.Path, parent.block.It, parent.key()
+			if !g.testAll(block.Header) {
+				continue
+			}
+
+		default:
+			continue
+		}
+
+		before := len(candidates)
+		candidates = g.findCandidates(block.ChildBlocks, match, next, candidates)
+
+		if next.matched && len(candidates) == before {
+			candidates = append(candidates, next)
+		}
+	}
+
+	return candidates
+}
+
+// Picks exactly one block for an input. Nested blocks are only candidates
+// when their own and every wrapping block's conditions are valid, and a
+// wrapping block is a fallback only when none of its nested blocks are.
+// Among candidates, the one matching the most explicit aliases wins, then
+// the most deeply nested, then whichever comes first in load order.
+func (g *Game) selectBlock(taleBlocks []blocks.Block, match matcher) (candidate, bool) {
+	// Headers are tested in the middle of running a block when using "do"
+	prevPath, prevIt, prevRepeatKey := g.path, g.it, g.repeatKey
+	defer func() { g.path, g.it, g.repeatKey = prevPath, prevIt, prevRepeatKey }()
+
+	candidates := g.findCandidates(taleBlocks, match, candidate{}, nil)
+	if len(candidates) == 0 {
+		return candidate{}, false
+	}
+
+	// Candidates are in load order, so ties keep the earliest
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.outranks(best) {
+			best = c
+		}
+	}
+
+	return best, true
+}
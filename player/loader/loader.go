@@ -0,0 +1,170 @@
+// Loads the .tale files which make up a tale in a stable order.
+//
+// Files are loaded in path order, except that any file named start.tale
+// comes before every other file. Paths are compared one directory at a
+// time, so "a/z.tale" comes before "a-b.tale". Every block keeps the path
+// of the file it came from, and each file's start block runs in load order
+// when a game starts.
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/parser"
+)
+
+const (
+	EXTENSION = ".tale"
+	START_FILE = "start.tale"
+)
+
+type File struct {
+	Path string
+	Blocks []blocks.Block
+	Errors []diagnostics.Diagnostic
+}
+
+// Finds every file with an extension in a directory and its subdirectories
+func FindNested(absDirPath string, extension string) []string {
+	var paths []string
+
+	entries, err := os.ReadDir(absDirPath)
+	if err != nil {
+		return paths
+	}
+
+	for _, entry := range entries {
+		absPath := path.Join(absDirPath, entry.Name())
+
+		if entry.IsDir() {
+			paths = append(paths, FindNested(absPath, extension)...)
+		} else if path.Ext(entry.Name()) == extension {
+			paths = append(paths, absPath)
+		}
+	}
+
+	return paths
+}
+
+// Resolves directory and .tale file arguments relative to a working
+// directory, defaulting to the working directory itself
+func FindPaths(pwd string, args []string) ([]string, error) {
+	var dirPaths, talePaths []string
+
+	for _, arg := range args {
+		absPath := arg
+		if !path.IsAbs(absPath) {
+			absPath = path.Join(pwd, absPath)
+		}
+
+		switch path.Ext(absPath) {
+		case EXTENSION:
+			talePaths = append(talePaths, absPath)
+		case "":
+			dirPaths = append(dirPaths, absPath)
+		default:
+			return nil, fmt.Errorf("Path must be a directory or .tale file %q", arg)
+		}
+	}
+
+	if len(dirPaths) == 0 && len(talePaths) == 0 {
+		dirPaths = append(dirPaths, pwd)
+	}
+
+	for _, dirPath := range dirPaths {
+		talePaths = append(talePaths, FindNested(dirPath, EXTENSION)...)
+	}
+
+	if len(talePaths) == 0 {
+		return nil, errors.New("No .tale files found!")
+	}
+
+	return talePaths, nil
+}
+
+func isStartFile(talePath string) bool {
+	return path.Base(talePath) == START_FILE
+}
+
+// Compares two paths by load order
+func Compare(a string, b string) int {
+	if isStartFile(a) != isStartFile(b) {
+		if isStartFile(a) {
+			return -1
+		}
+		return 1
+	}
+
+	return slices.Compare(strings.Split(path.Clean(a), "/"), strings.Split(path.Clean(b), "/"))
+}
+
+func parseFile(absTalePath string) File {
+	file := File{Path: absTalePath}
+
+	p, err := parser.New(absTalePath)
+	if err != nil {
+		var diag diagnostics.Diagnostic
+		if !errors.As(err, &diag) {
+			diag = diagnostics.Diagnostic{Path: absTalePath, Message: err.Error()}
+		}
+		file.Errors = append(file.Errors, diag)
+		return file
+	}
+
+	for block := p.Next(); block.Type != blocks.END_OF_BLOCKS; block = p.Next() {
+		file.Blocks = append(file.Blocks, block)
+	}
+
+	file.Errors = p.Errors()
+	return file
+}
+
+// Parses each file concurrently, returning them in load order. Paths
+// listed more than once are only loaded once.
+func Load(talePaths []string) []File {
+	talePaths = slices.Clone(talePaths)
+	slices.SortFunc(talePaths, Compare)
+	talePaths = slices.Compact(talePaths)
+
+	files := make([]File, len(talePaths))
+	var wg sync.WaitGroup
+
+	for i, talePath := range talePaths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			files[i] = parseFile(talePath)
+		}()
+	}
+
+	wg.Wait()
+	return files
+}
+
+// Every block from every file, in load order
+func Blocks(files []File) []blocks.Block {
+	var taleBlocks []blocks.Block
+
+	for _, file := range files {
+		taleBlocks = append(taleBlocks, file.Blocks...)
+	}
+
+	return taleBlocks
+}
+
+func Errors(files []File) []diagnostics.Diagnostic {
+	var diags []diagnostics.Diagnostic
+
+	for _, file := range files {
+		diags = append(diags, file.Errors...)
+	}
+
+	return diags
+}
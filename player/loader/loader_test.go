@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, sources map[string]string) {
+	for name, source := range sources {
+		talePath := path.Join(dir, name)
+		if err := os.MkdirAll(path.Dir(talePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(talePath, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	paths := []string{
+		"/tale/rooms/start.tale",
+		"/tale/b.tale",
+		"/tale/a-b.tale",
+		"/tale/a/z.tale",
+		"/tale/start.tale",
+		"/tale/A.tale",
+	}
+	expected := []string{
+		"/tale/rooms/start.tale",
+		"/tale/start.tale",
+		"/tale/A.tale",
+		"/tale/a/z.tale",
+		"/tale/a-b.tale",
+		"/tale/b.tale",
+	}
+
+	slices.SortFunc(paths, Compare)
+	if !slices.Equal(paths, expected) {
+		t.Fatalf("wrong order, expected=%v, got=%v", expected, paths)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"start-only/start-only-2.tale": "Willkommen!",
+		"start-only/start-only-1.tale": "Welcome!",
+		"inputs.tale": "> greet >\nHullo there",
+		"start.tale": "Greetings!",
+		"broken.tale": "{set",
+	})
+
+	talePaths, err := FindPaths(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Listing a file again should not load it twice
+	files := Load(append(talePaths, path.Join(dir, "inputs.tale")))
+
+	expected := []string{"start.tale", "broken.tale", "inputs.tale", "start-only/start-only-1.tale", "start-only/start-only-2.tale"}
+	if len(files) != len(expected) {
+		t.Fatalf("wrong number of files, expected=%d, got=%d", len(expected), len(files))
+	}
+
+	for i, file := range files {
+		if file.Path != path.Join(dir, expected[i]) {
+			t.Fatalf("wrong file at %d, expected=%s, got=%s", i, expected[i], file.Path)
+		}
+		for _, block := range file.Blocks {
+			if block.Path != file.Path {
+				t.Fatalf("block from %s has path %s", file.Path, block.Path)
+			}
+		}
+	}
+
+	taleBlocks := Blocks(files)
+	if len(taleBlocks) != 5 || taleBlocks[0].Path != files[0].Path || taleBlocks[4].Path != files[4].Path {
+		t.Fatalf("unexpected blocks %v", taleBlocks)
+	}
+
+	if errors := Errors(files); len(errors) != 1 || errors[0].Path != files[1].Path {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+}
+
+func TestFindPathsErrors(t *testing.T) {
+	if _, err := FindPaths(t.TempDir(), nil); err == nil {
+		t.Fatalf("expected an error for a directory without .tale files")
+	}
+	if _, err := FindPaths(t.TempDir(), []string{"notes.txt"}); err == nil {
+		t.Fatalf("expected an error for a file which is not a .tale file")
+	}
+}
@@ -1,100 +1,77 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
-	"path"
-	"tale/blocks"
-	"tale/parser"
+	"strconv"
+	"tale/loader"
 )
 
-func findNestedTalePaths(absDirPath string) []string {
-	var talePaths []string
-
-	entries, err := os.ReadDir(absDirPath)
+// Resolves directory and .tale file arguments to absolute .tale file paths,
+// defaulting to the working directory
+func findTalePaths(args []string) []string {
+	pwd, err := os.Getwd()
 	if err != nil {
-		return talePaths
+		log.Fatal(err)
 	}
 
-	for _, entry := range entries {
-		absPath := path.Join(absDirPath, entry.Name())
-
-		if entry.IsDir() {
-			talePaths = append(talePaths, findNestedTalePaths(absPath)...)
-		} else if path.Ext(entry.Name()) == ".tale" {
-			talePaths = append(talePaths, absPath)
-		}
+	talePaths, err := loader.FindPaths(pwd, args)
+	if err != nil {
+		log.Fatalf("Error: %v\n", err)
 	}
 
 	return talePaths
 }
 
-func streamTaleFile(absTalePath string, blockChan chan blocks.Block) {
-	p := parser.New(absTalePath)
-	block := p.Next()
+// Prints every error in the files, returning false if there were any
+func reportErrors(files []loader.File) bool {
+	ok := true
 
-	for block.Type != blocks.END_OF_BLOCKS {
-		blockChan <- block
-		block = p.Next()
+	for _, err := range loader.Errors(files) {
+		fmt.Fprintln(os.Stderr, err)
+		ok = false
 	}
 
-	blockChan <- block
+	return ok
 }
 
-func main() {
-	pwd, err := os.Getwd()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	var dirPaths, talePaths []string
-
-	for _, arg := range os.Args[1:] {
-		absPath := arg
-		if !path.IsAbs(absPath) {
-			absPath = path.Join(pwd, absPath)
-		}
-
-		switch path.Ext(absPath) {
-		case ".tale":
-			talePaths = append(talePaths, absPath)
-		case "":
-			dirPaths = append(dirPaths, absPath)
-		default:
-			log.Fatalf("Error: Path must be a directory or .tale file %q\n", arg)
-		}
-	}
-
-	if len(dirPaths) == 0 && len(talePaths) == 0 {
-		dirPaths = append(dirPaths, pwd)
-	}
-
-	for _, dirPath := range dirPaths {
-		talePaths = append(talePaths, findNestedTalePaths(dirPath)...)
-	}
-
-	if len(talePaths) == 0 {
-		log.Fatal("Error: No .tale files found!")
-	}
-
-	blockChan := make(chan blocks.Block)
-
-	for _, talePath := range talePaths {
-		go streamTaleFile(talePath, blockChan)
-	}
-
-	var streamedBlocks []blocks.Block
+// Adds a --seed flag, which defaults to a random seed when it is not given
+func seedFlag(flags *flag.FlagSet) *uint64 {
+	seed := rand.Uint64()
+	flags.Func("seed", "seed for random choices, to replay a game exactly (default random)", func(value string) error {
+		var err error
+		seed, err = strconv.ParseUint(value, 10, 64)
+		return err
+	})
+	return &seed
+}
 
-	for doneCount := 0; doneCount < len(talePaths); {
-		block :=  <-blockChan
+func printBlocks(args []string) {
+	files := loader.Load(findTalePaths(args))
+	reportErrors(files)
+	fmt.Printf("Blocks:\n%v\n", loader.Blocks(files))
+}
 
-		if block.Type == blocks.END_OF_BLOCKS {
-			doneCount += 1
-		} else {
-			streamedBlocks = append(streamedBlocks, block)
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "play":
+			play(os.Args[2:])
+			return
+		case "check":
+			checkTale(os.Args[2:])
+			return
+		case "test":
+			testTale(os.Args[2:])
+			return
+		case "export":
+			exportTale(os.Args[2:])
+			return
 		}
 	}
 
-	fmt.Printf("Blocks:\n%q\n", streamedBlocks)
+	printBlocks(os.Args[1:])
 }
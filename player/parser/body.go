@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"tale/blocks"
+	"tale/tokens"
+)
+
+// Actions are opened tentatively, since whether {set score} is a simple
+// action or encloses text is only known once a matching {/set} is found
+type bodyFrame struct {
+	opener blocks.BodyNode
+	nodes []blocks.BodyNode
+}
+
+func getOpenerName(node blocks.BodyNode) string {
+	switch {
+	case node.Type == blocks.ACTION_NODE:
+		return node.Name
+	case node.Type == blocks.INTERPOLATION_NODE && len(node.Args) == 1 && blocks.IsBareName(node.Args[0]):
+		return node.Args[0].Token.Literal
+	default:
+		return ""
+	}
+}
+
+func (p *Parser) parseAction() blocks.BodyNode {
+	node := blocks.BodyNode{Token: p.next}
+	p.advance()
+
+	args := p.parseExpressionList()
+	if p.next.Type == tokens.ACTION_END {
+		p.advance()
+	} else {
+		p.errorf(node.Token, "{ is missing a closing }")
+	}
+
+	if len(args) == 0 {
+		p.errorf(node.Token, "{} is empty, expected an action or value")
+	}
+
+	if len(args) > 0 && blocks.IsBareName(args[0]) {
+		name := args[0].Token.Literal
+
+		if blocks.IsAction(name) || len(args) > 1 {
+			node.Type = blocks.ACTION_NODE
+			node.Name = name
+			node.Args = args[1:]
+			return node
+		}
+	}
+
+	if len(args) > 1 {
+		p.errorf(args[0].Token, "expected an action name but found %q", args[0].Token.Literal)
+	}
+
+	node.Type = blocks.INTERPOLATION_NODE
+	node.Args = args
+	return node
+}
+
+func (p *Parser) parseActionEnd() (string, tokens.Token) {
+	token := p.next
+	name := ""
+	p.advance()
+
+	if p.next.Type == tokens.NAME {
+		name = p.next.Literal
+		p.advance()
+	} else {
+		p.errorf(token, "{/ must be followed by the name of an action")
+	}
+
+	for !p.atExpressionEnd() {
+		p.errorf(p.next, "unexpected %q, closing actions only include a name", p.next.Literal)
+		p.advance()
+	}
+
+	if p.next.Type == tokens.ACTION_END {
+		p.advance()
+	} else {
+		p.errorf(token, "{/ is missing a closing }")
+	}
+
+	return name, token
+}
+
+// Moves the top frame's opener and nodes into its parent as siblings
+func (p *Parser) flattenFrame(frames []bodyFrame) []bodyFrame {
+	top := frames[len(frames) - 1]
+	parent := &frames[len(frames) - 2]
+	name := getOpenerName(top.opener)
+
+	if blocks.IsEnclosingAction(name) {
+		p.errorf(top.opener.Token, "{%s} is missing a closing {/%s}", name, name)
+	}
+
+	parent.nodes = append(parent.nodes, top.opener)
+	parent.nodes = append(parent.nodes, top.nodes...)
+	return frames[:len(frames) - 1]
+}
+
+func (p *Parser) closeFrame(frames []bodyFrame) []bodyFrame {
+	name, token := p.parseActionEnd()
+
+	match := 0
+	for i := len(frames) - 1; i > 0; i-- {
+		if getOpenerName(frames[i].opener) == name {
+			match = i
+			break
+		}
+	}
+
+	if match == 0 {
+		p.errorf(token, "{/%s} has no matching opening {%s}", name, name)
+		return frames
+	}
+
+	for len(frames) - 1 > match {
+		frames = p.flattenFrame(frames)
+	}
+
+	top := frames[len(frames) - 1]
+	frames = frames[:len(frames) - 1]
+
+	node := top.opener
+	if node.Type == blocks.INTERPOLATION_NODE {
+		node.Args = nil
+	}
+	node.Type = blocks.ENCLOSING_NODE
+	node.Name = name
+	node.Children = top.nodes
+
+	parent := &frames[len(frames) - 1]
+	parent.nodes = append(parent.nodes, node)
+	return frames
+}
+
+func (p *Parser) parseBody() []blocks.BodyNode {
+	frames := []bodyFrame{{}}
+
+	for !p.atBlockEnd() {
+		top := &frames[len(frames) - 1]
+
+		switch p.next.Type {
+		case tokens.TEXT:
+			top.nodes = append(top.nodes, blocks.BodyNode{
+				Type: blocks.TEXT_NODE,
+				Token: p.next,
+				Text: p.next.Literal,
+			})
+			p.advance()
+
+		case tokens.ACTION:
+			node := p.parseAction()
+			if getOpenerName(node) != "" {
+				frames = append(frames, bodyFrame{opener: node})
+			} else {
+				top.nodes = append(top.nodes, node)
+			}
+
+		case tokens.ENCLOSING_ACTION:
+			frames = p.closeFrame(frames)
+
+		default:
+			p.advance()
+		}
+	}
+
+	for len(frames) > 1 {
+		frames = p.flattenFrame(frames)
+	}
+
+	return frames[0].nodes
+}
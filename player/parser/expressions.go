@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"tale/blocks"
+	"tale/tokens"
+	"unicode/utf8"
+)
+
+type precedence uint8
+
+// Lowest to highest, following the order of operations in the overview
+const (
+	lowest precedence = iota
+	logicalOr
+	logicalAnd
+	logicalNot
+	relation
+	comparison
+	sum
+	product
+	prefix
+	access
+	colonAccess
+)
+
+func getPrecedence(t tokens.TokenType) precedence {
+	switch t {
+	case tokens.OR:
+		return logicalOr
+	case tokens.AND:
+		return logicalAnd
+	case tokens.IS, tokens.HAS, tokens.IN, tokens.WITH, tokens.NOT:
+		return relation
+	case tokens.GT, tokens.LT, tokens.GTE, tokens.LTE:
+		return comparison
+	case tokens.PLUS, tokens.MINUS:
+		return sum
+	case tokens.MULTIPLY, tokens.DIVIDE, tokens.REMAINDER:
+		return product
+	case tokens.OF:
+		return access
+	case tokens.COLON:
+		return colonAccess
+	default:
+		return lowest
+	}
+}
+
+func isRelation(t tokens.TokenType) bool {
+	return t == tokens.IS ||
+		t == tokens.HAS ||
+		t == tokens.IN ||
+		t == tokens.WITH
+}
+
+func (p *Parser) atExpressionEnd() bool {
+	return p.next.Type == tokens.EOF ||
+		p.next.Type == tokens.HEADER_END ||
+		p.next.Type == tokens.ACTION_END
+}
+
+// A minus separated from the previous token but attached to the next one
+// starts a new negative expression rather than subtracting, e.g. the second
+// input of {set balance -1000}
+func (p *Parser) atNegativeStart() bool {
+	if p.next.Type != tokens.MINUS {
+		return false
+	}
+
+	prevEnd := p.prev.Column + utf8.RuneCountInString(p.prev.Literal)
+	if p.prev.Type == tokens.TEXT {
+		prevEnd += 2
+	}
+
+	spacedBefore := p.prev.Line != p.next.Line || prevEnd < p.next.Column
+	attachedAfter := p.peek.Line == p.next.Line && p.peek.Column == p.next.Column + 1
+
+	return spacedBefore && attachedAfter
+}
+
+func (p *Parser) parseExpressionList() []blocks.Expression {
+	var expressions []blocks.Expression
+
+	for !p.atExpressionEnd() {
+		expressions = append(expressions, p.parseExpression(lowest))
+	}
+
+	return expressions
+}
+
+func (p *Parser) parseExpression(prec precedence) blocks.Expression {
+	left := p.parsePrefix()
+
+	for !p.atExpressionEnd() && !p.atNegativeStart() && prec < getPrecedence(p.next.Type) {
+		left = p.parseInfix(left)
+	}
+
+	return left
+}
+
+func (p *Parser) parsePrefix() blocks.Expression {
+	if p.atExpressionEnd() {
+		p.errorf(p.prev, "expected a value after %q", p.prev.Literal)
+		return blocks.Expression{}
+	}
+
+	token := p.next
+	p.advance()
+
+	switch token.Type {
+	case tokens.NOT:
+		right := p.parseExpression(logicalNot)
+		return blocks.Expression{Token: token, Right: &right}
+
+	case tokens.MINUS:
+		right := p.parseExpression(prefix)
+		return blocks.Expression{Token: token, Right: &right}
+
+	case tokens.PAREN:
+		inner := p.parseExpression(lowest)
+		if p.next.Type == tokens.PAREN_END {
+			p.advance()
+		} else {
+			p.errorf(token, "( is missing a closing )")
+		}
+		return inner
+
+	case tokens.NAME, tokens.NUMBER, tokens.TEXT, tokens.FLAG, tokens.IT:
+		return blocks.Expression{Token: token}
+
+	case tokens.INVALID:
+		p.errorf(token, "%q is not allowed here", token.Literal)
+		return blocks.Expression{Token: token}
+
+	default:
+		p.errorf(token, "expected a value but found %q", token.Literal)
+		return blocks.Expression{Token: token}
+	}
+}
+
+func (p *Parser) parseInfix(left blocks.Expression) blocks.Expression {
+	token := p.next
+	p.advance()
+
+	switch {
+	// Negated relations like "door is not locked" or "player not with it"
+	// wrap the whole relation in a single "not"
+	case token.Type == tokens.IS && p.next.Type == tokens.NOT:
+		notToken := p.next
+		p.advance()
+		relation := p.parseRelation(token, left)
+		return blocks.Expression{Token: notToken, Right: &relation}
+
+	case token.Type == tokens.NOT && isRelation(p.next.Type):
+		relationToken := p.next
+		p.advance()
+		relation := p.parseRelation(relationToken, left)
+		return blocks.Expression{Token: token, Right: &relation}
+
+	case token.Type == tokens.NOT:
+		p.errorf(token, "expected is, has, in, or with after %q", token.Literal)
+		right := p.parseExpression(relation)
+		return blocks.Expression{Token: token, Left: &left, Right: &right}
+
+	// Access with "of" is right associative: name of location of player
+	case token.Type == tokens.OF:
+		right := p.parseExpression(access - 1)
+		return blocks.Expression{Token: token, Left: &left, Right: &right}
+
+	default:
+		right := p.parseExpression(getPrecedence(token.Type))
+		return blocks.Expression{Token: token, Left: &left, Right: &right}
+	}
+}
+
+func (p *Parser) parseRelation(token tokens.Token, left blocks.Expression) blocks.Expression {
+	right := p.parseExpression(relation)
+	return blocks.Expression{Token: token, Left: &left, Right: &right}
+}
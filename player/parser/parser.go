@@ -1,10 +1,12 @@
 package parser
 
 import (
-	"log"
+	"errors"
+	"io/fs"
 	"os"
-	"tale/lexer"
 	"tale/blocks"
+	"tale/diagnostics"
+	"tale/lexer"
 	"tale/tokens"
 )
 
@@ -12,60 +14,132 @@ type Parser struct {
 	path string
 	input string
 	lexer *lexer.Lexer
+	prev tokens.Token
 	next tokens.Token
+	peek tokens.Token
 	blockCount uint
+	errors []diagnostics.Diagnostic
 }
 
 func (p *Parser) advance() {
-	p.next = p.lexer.Next()
+	p.prev = p.next
+	p.next = p.peek
+	p.peek = p.lexer.Next()
 }
 
 func (p *Parser) atBlockEnd() bool {
-	return p.next.Type == tokens.EOF ||
-		p.next.Type == tokens.INPUT_HEADER ||
+	return p.next.Type == tokens.EOF || p.atHeader()
+}
+
+func (p *Parser) errorf(token tokens.Token, format string, args ...any) {
+	p.errors = append(p.errors, diagnostics.New(p.path, token, format, args...))
+}
+
+func (p *Parser) atHeader() bool {
+	return p.next.Type == tokens.INPUT_HEADER ||
 		p.next.Type == tokens.STATE_HEADER
 }
 
+// Input and state blocks nest within each other based only on the
+// number of header characters, so ">>" may follow "=" and vice versa
 func (p *Parser) atNestedBlockStart(depth int) bool {
-	return p.next.Type == tokens.INPUT_HEADER &&
+	return p.atHeader() &&
 		depth > 0 &&
 		depth < len(p.next.Literal)
 }
 
-func (p *Parser) parseInputHeader() []blocks.Expression {
-	var header []blocks.Expression
+func (p *Parser) parseHeader() []blocks.Expression {
+	start := p.next
 	p.advance()
+	header := p.parseExpressionList()
 
-	for p.next.Type != tokens.HEADER_END {
-		header = append(header, blocks.Expression{Token: p.next})
+	if p.next.Type == tokens.HEADER_END {
 		p.advance()
 	}
 
-	p.advance()
-	return header
-}
-
-func New(absTalePath string) *Parser {
-	taleBytes, err := os.ReadFile(absTalePath)
-	if err != nil {
-		log.Fatal(err)
+	switch {
+	case len(header) == 0 && start.Type == tokens.INPUT_HEADER:
+		p.errorf(start, "input header is missing an input")
+	case len(header) == 0:
+		p.errorf(start, "state header is missing a condition")
+	case len(header) > 1 && start.Type == tokens.STATE_HEADER:
+		p.errorf(header[1].Token, "state header must be a single condition, combine conditions with \"and\" or \"or\"")
 	}
 
-	input := string(taleBytes)
+	return header
+}
 
+func newParser(path string, input string) *Parser {
 	p := &Parser{
-		path: absTalePath,
+		path: path,
 		input: input,
 		lexer: lexer.New(input),
 		blockCount: 0,
 	}
 
 	p.next = p.lexer.Next()
+	p.peek = p.lexer.Next()
 	return p
 }
 
+// Returns a diagnostics.Diagnostic for the whole file if it cannot be read
+func New(absTalePath string) (*Parser, error) {
+	taleBytes, err := os.ReadFile(absTalePath)
+	if err != nil {
+		message := err.Error()
+
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			message = pathErr.Err.Error()
+		}
+
+		return nil, diagnostics.Diagnostic{Path: absTalePath, Message: message}
+	}
+
+	return newParser(absTalePath, string(taleBytes)), nil
+}
+
+// Parses every block of a tale file from text that is already in memory,
+// reporting positions within path
+func Parse(path string, input string) ([]blocks.Block, []diagnostics.Diagnostic) {
+	p := newParser(path, input)
+
+	var taleBlocks []blocks.Block
+	for block := p.Next(); block.Type != blocks.END_OF_BLOCKS; block = p.Next() {
+		taleBlocks = append(taleBlocks, block)
+	}
+	return taleBlocks, p.Errors()
+}
+
+// Parses a single condition as it would be written in a state header, for
+// checking the state of a game from outside a tale
+func ParseCondition(path string, condition string) (blocks.Expression, []diagnostics.Diagnostic) {
+	p := newParser(path, "= " + condition + " =")
+	block := p.Next()
+
+	// Report positions within the condition rather than the header
+	errors := p.Errors()
+	for i := range errors {
+		errors[i].Column = max(errors[i].Column - 2, 1)
+	}
+
+	if len(errors) > 0 || len(block.Header) != 1 {
+		return blocks.Expression{}, errors
+	}
+	return block.Header[0], nil
+}
+
+// Every problem found in the blocks parsed so far, in the order found
+func (p *Parser) Errors() []diagnostics.Diagnostic {
+	return p.errors
+}
+
 func (p *Parser) Next() blocks.Block {
-	var block blocks.Block
+	block := blocks.Block{
+		Path: p.path,
+		Line: p.next.Line,
+		Column: p.next.Column,
+	}
 	depth := 0
 
 	if p.next.Type == tokens.EOF {
@@ -73,10 +147,15 @@ func (p *Parser) Next() blocks.Block {
 		return block
 	}
 
-	if p.next.Type == tokens.INPUT_HEADER {
+	switch p.next.Type {
+	case tokens.INPUT_HEADER:
 		depth = len(p.next.Literal)
 		block.Type = blocks.INPUT
-		block.Header = p.parseInputHeader()
+		block.Header = p.parseHeader()
+	case tokens.STATE_HEADER:
+		depth = len(p.next.Literal)
+		block.Type = blocks.STATE
+		block.Header = p.parseHeader()
 	}
 
 	if block.Type == 0 && p.blockCount == 0 {
@@ -84,13 +163,7 @@ func (p *Parser) Next() blocks.Block {
 	}
 	p.blockCount += 1
 
-	for !p.atBlockEnd() {
-		switch p.next.Type {
-		case tokens.TEXT:
-			block.Body = append(block.Body, blocks.BodyNode{p.next.Literal})
-		}
-		p.advance()
-	}
+	block.Body = p.parseBody()
 
 	for p.atNestedBlockStart(depth) {
 		block.ChildBlocks = append(block.ChildBlocks, p.Next())
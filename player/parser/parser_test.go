@@ -0,0 +1,361 @@
+package parser
+
+import (
+	"errors"
+	"tale/blocks"
+	"tale/diagnostics"
+	"testing"
+)
+
+func expectExpressions(t *testing.T, input string, expected []string) {
+	p := newParser("test.tale", input)
+	p.advance()
+	actual := p.parseExpressionList()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d expressions, got %d: %v", len(expected), len(actual), actual)
+	}
+
+	for i, exp := range expected {
+		if actual[i].String() != exp {
+			t.Fatalf("[%d] expected=%s, got=%s", i, exp, actual[i])
+		}
+	}
+}
+
+func expectHeader(t *testing.T, input string, expected []string) {
+	block := newParser("test.tale", input).Next()
+
+	if len(block.Header) != len(expected) {
+		t.Fatalf("expected %d expressions, got %d: %v", len(expected), len(block.Header), block.Header)
+	}
+
+	for i, exp := range expected {
+		if block.Header[i].String() != exp {
+			t.Fatalf("[%d] expected=%s, got=%s", i, exp, block.Header[i])
+		}
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	expectExpressions(t, `{score 3 "Alice" yes it}`, []string{
+		"score",
+		"3",
+		`"Alice"`,
+		"yes",
+		"it",
+	})
+}
+
+func TestArithmetic(t *testing.T) {
+	expectExpressions(t, "{1 + 2 * 3 - 4 / 5 % 6}", []string{
+		"(- (+ 1 (* 2 3)) (% (/ 4 5) 6))",
+	})
+
+	expectExpressions(t, "{(score * 3 / 3 - 0) % 1}", []string{
+		"(% (- (/ (* score 3) 3) 0) 1)",
+	})
+
+	expectExpressions(t, "{goal - (score + 1) >= 2 * lives}", []string{
+		"(>= (- goal (+ score 1)) (* 2 lives))",
+	})
+}
+
+func TestNegatives(t *testing.T) {
+	expectExpressions(t, "{set balance -1000}", []string{
+		"set",
+		"balance",
+		"(- 1000)",
+	})
+
+	expectExpressions(t, "{goal - score}", []string{
+		"(- goal score)",
+	})
+
+	expectExpressions(t, "{goal-score}", []string{
+		"(- goal score)",
+	})
+
+	expectExpressions(t, "{-goal * -(2 - 3)}", []string{
+		"(* (- goal) (- (- 2 3)))",
+	})
+}
+
+func TestAccess(t *testing.T) {
+	expectExpressions(t, "{set score of player player:score + 1}", []string{
+		"set",
+		"(of score player)",
+		"(+ (: player score) 1)",
+	})
+
+	expectExpressions(t, "{name of location of player}", []string{
+		"(of name (of location player))",
+	})
+
+	expectExpressions(t, "{name of player:location}", []string{
+		"(of name (: player location))",
+	})
+}
+
+func TestConditions(t *testing.T) {
+	expectExpressions(t, "{room has door and door of room is broken}", []string{
+		"(and (has room door) (is (of door room) broken))",
+	})
+
+	expectExpressions(t, "{player with teacher or player is not chastised}", []string{
+		"(or (with player teacher) (not (is player chastised)))",
+	})
+
+	expectExpressions(t, "{not a or b and not c}", []string{
+		"(or (not a) (and b (not c)))",
+	})
+
+	expectExpressions(t, "{not door is locked}", []string{
+		"(not (is door locked))",
+	})
+
+	expectExpressions(t, "{player not with it and player not has rope}", []string{
+		"(and (not (with player it)) (not (has player rope)))",
+	})
+
+	expectExpressions(t, "{score + 1 is goal and button in room}", []string{
+		"(and (is (+ score 1) goal) (in button room))",
+	})
+}
+
+func TestInputHeader(t *testing.T) {
+	expectHeader(t, "> greet dismiss >\nHuh?", []string{
+		"greet",
+		"dismiss",
+	})
+
+	expectHeader(t, "> go", []string{
+		"go",
+	})
+}
+
+func TestStateHeader(t *testing.T) {
+	expectHeader(t, "= room is dark =\nIt's too dark!", []string{
+		"(is room dark)",
+	})
+
+	expectHeader(t, `== "wasted action" ==`, []string{
+		`"wasted action"`,
+	})
+}
+
+func TestNestedBlocks(t *testing.T) {
+	input := `Welcome!
+
+= room is dark =
+It's too dark!
+
+>> light >>
+>>> torch >>>
+The torch blazes to life.
+
+=== repeat ===
+Still lit.
+
+>> look >>
+You can't see.
+
+= room is not dark =
+
+> look >
+== repeat ==
+`
+
+	p := newParser("test.tale", input)
+
+	start := p.Next()
+	if start.Type != blocks.START || len(start.ChildBlocks) != 0 {
+		t.Fatalf("expected childless start block, got %v", start.Type)
+	}
+
+	dark := p.Next()
+	if dark.Type != blocks.STATE || len(dark.ChildBlocks) != 2 {
+		t.Fatalf("expected state block with 2 children, got %v with %d", dark.Type, len(dark.ChildBlocks))
+	}
+
+	light := dark.ChildBlocks[0]
+	if light.Type != blocks.INPUT || len(light.ChildBlocks) != 2 {
+		t.Fatalf("expected input block with 2 children, got %v with %d", light.Type, len(light.ChildBlocks))
+	}
+
+	if light.ChildBlocks[0].Type != blocks.INPUT {
+		t.Fatalf("expected input block, got %v", light.ChildBlocks[0].Type)
+	}
+
+	if light.ChildBlocks[1].Type != blocks.STATE {
+		t.Fatalf("expected state block, got %v", light.ChildBlocks[1].Type)
+	}
+
+	if dark.ChildBlocks[1].Type != blocks.INPUT || len(dark.ChildBlocks[1].ChildBlocks) != 0 {
+		t.Fatalf("expected childless input block, got %v", dark.ChildBlocks[1].Type)
+	}
+
+	notDark := p.Next()
+	if notDark.Type != blocks.STATE || len(notDark.ChildBlocks) != 0 {
+		t.Fatalf("expected childless state block, got %v", notDark.Type)
+	}
+
+	look := p.Next()
+	if look.Type != blocks.INPUT || len(look.ChildBlocks) != 1 {
+		t.Fatalf("expected input block with 1 child, got %v", look.Type)
+	}
+
+	if end := p.Next(); end.Type != blocks.END_OF_BLOCKS {
+		t.Fatalf("expected end of blocks, got %v", end.Type)
+	}
+}
+
+func expectBody(t *testing.T, input string, expected []string, expectedErrors int) {
+	p := newParser("test.tale", input)
+	block := p.Next()
+
+	if len(block.Body) != len(expected) {
+		t.Fatalf("expected %d nodes, got %d: %v", len(expected), len(block.Body), block.Body)
+	}
+
+	for i, exp := range expected {
+		if block.Body[i].String() != exp {
+			t.Fatalf("[%d] expected=%s, got=%s", i, exp, block.Body[i])
+		}
+	}
+
+	if len(p.Errors()) != expectedErrors {
+		t.Fatalf("expected %d errors, got %d: %v", expectedErrors, len(p.Errors()), p.Errors())
+	}
+}
+
+func TestActionNodes(t *testing.T) {
+	expectBody(t, "{set score 3}Your score is {score}!{win_game now}{unset}", []string{
+		"{set score 3}",
+		`"Your score is "`,
+		"{score}",
+		`"!"`,
+		"{win_game now}",
+		"{unset}",
+	}, 0)
+
+	expectBody(t, "You see {name of player} and {it}. {(1 + 2)}", []string{
+		`"You see "`,
+		"{(of name player)}",
+		`" and "`,
+		"{it}",
+		`". "`,
+		"{(+ 1 2)}",
+	}, 0)
+}
+
+func TestEnclosingNodes(t *testing.T) {
+	expectBody(t, "Here comes my {b}MEGA{/b} move!", []string{
+		`"Here comes my "`,
+		`{b}"MEGA"{/b}`,
+		`" move!"`,
+	}, 0)
+
+	expectBody(t, "{set description}A {i}dark{/i} tower{/set}{set lit}", []string{
+		`{set description}"A "{i}"dark"{/i}" tower"{/set}`,
+		"{set lit}",
+	}, 0)
+
+	expectBody(t, "{piece}{alias rope}{/piece}", []string{
+		"{piece}{alias rope}{/piece}",
+	}, 0)
+
+	input := `{choose}
+{choice player is strong}You bust through!{/choice}
+{choice}You're {score} {b}screwed{/b}{/choice}
+{/choose}`
+
+	expectBody(t, input, []string{
+		`{choose}{choice (is player strong)}"You bust through!"{/choice}"\n"` +
+			`{choice}"You're "{score}" "{b}"screwed"{/b}{/choice}"\n"{/choose}`,
+	}, 0)
+}
+
+func TestMismatchedEnclosingNodes(t *testing.T) {
+	expectBody(t, "Oops{/b} done", []string{
+		`"Oops"`,
+		`" done"`,
+	}, 1)
+
+	expectBody(t, "{b}{i}crossed{/b}{/i}", []string{
+		`{b}{i}"crossed"{/b}`,
+	}, 2)
+
+	expectBody(t, "{title}Unclosed", []string{
+		"{title}",
+		`"Unclosed"`,
+	}, 1)
+}
+
+func expectErrors(t *testing.T, input string, expected []string) {
+	p := newParser("test.tale", input)
+	for block := p.Next(); block.Type != blocks.END_OF_BLOCKS; block = p.Next() {
+	}
+
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+
+	for i, exp := range expected {
+		if errors[i].Error() != exp {
+			t.Fatalf("[%d] expected=%q, got=%q", i, exp, errors[i].Error())
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	input := `{set score 1 +}
+> $bling <
+= dark light =
+==
+{3 score}{(1 + 2}
+{b}Bold{/i}{/b}
+{player not score}
+{set`
+
+	expectErrors(t, input, []string{
+		`test.tale:1:14: error: expected a value after "+"`,
+		`test.tale:2:3: error: "$" is not allowed here`,
+		`test.tale:2:10: error: expected a value after "<"`,
+		`test.tale:3:8: error: state header must be a single condition, combine conditions with "and" or "or"`,
+		`test.tale:4:1: error: state header is missing a condition`,
+		`test.tale:5:2: error: expected an action name but found "3"`,
+		`test.tale:5:11: error: ( is missing a closing )`,
+		`test.tale:6:8: error: {/i} has no matching opening {i}`,
+		`test.tale:7:9: error: expected is, has, in, or with after "not"`,
+		`test.tale:8:1: error: { is missing a closing }`,
+	})
+}
+
+func TestParse(t *testing.T) {
+	taleBlocks, errors := Parse("test.tale", "Welcome!\n\n> greet >\nHello {(score}")
+	if len(taleBlocks) != 2 || taleBlocks[1].Path != "test.tale" || taleBlocks[1].Type != blocks.INPUT {
+		t.Fatalf("unexpected blocks %v", taleBlocks)
+	}
+
+	if len(errors) != 1 || errors[0].Error() != "test.tale:4:8: error: ( is missing a closing )" {
+		t.Fatalf("unexpected errors %v", errors)
+	}
+}
+
+func TestMissingFile(t *testing.T) {
+	_, err := New("/tale/does/not/exist.tale")
+	if err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+
+	var diag diagnostics.Diagnostic
+	if !errors.As(err, &diag) || diag.Path != "/tale/does/not/exist.tale" {
+		t.Fatalf("expected a diagnostic for the file, got %#v", err)
+	}
+
+	if err.Error() != "/tale/does/not/exist.tale: error: no such file or directory" {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+}
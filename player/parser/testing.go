@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"tale/blocks"
+	"testing"
+)
+
+// Parses a tale file for a test, failing the test on any parse errors
+func MustParse(t testing.TB, path string, input string) []blocks.Block {
+	t.Helper()
+
+	taleBlocks, errors := Parse(path, input)
+	if len(errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", errors)
+	}
+	return taleBlocks
+}
+
+// Parses each source as a file named "1.tale", "2.tale", and so on within
+// dir, failing the test on any parse errors
+func MustParseFiles(t testing.TB, dir string, sources ...string) []blocks.Block {
+	t.Helper()
+
+	var taleBlocks []blocks.Block
+	for i, source := range sources {
+		path := filepath.Join(dir, fmt.Sprintf("%d.tale", i + 1))
+		taleBlocks = append(taleBlocks, MustParse(t, path, source)...)
+	}
+	return taleBlocks
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"tale/game"
+	"tale/loader"
+	"tale/transcript"
+)
+
+func reportRuntimeErrors(g *game.Game) {
+	for _, err := range g.Errors() {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+const defaultSavePath = "tale-save.json"
+
+func saveGame(g *game.Game, savePath string) error {
+	f, err := os.Create(savePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return g.Save(f)
+}
+
+func loadGame(g *game.Game, savePath string) error {
+	f, err := os.Open(savePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return g.Load(f)
+}
+
+// Handles "save" and "load", optionally followed by a .json file, returning
+// false for any other input so it can be passed on to the tale
+func runMetaCommand(g *game.Game, recorder *transcript.Recorder, input string) bool {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || len(fields) > 2 {
+		return false
+	}
+
+	savePath := defaultSavePath
+	if len(fields) == 2 {
+		if path.Ext(fields[1]) != ".json" {
+			return false
+		}
+		savePath = fields[1]
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "save":
+		if err := saveGame(g, savePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		} else {
+			fmt.Printf("(saved to %s)\n\n", savePath)
+		}
+	case "load":
+		if recorder != nil {
+			fmt.Fprintln(os.Stderr, "Error: Cannot load while recording, the transcript would not replay")
+		} else if err := loadGame(g, savePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		} else {
+			fmt.Printf("(loaded %s)\n\n", savePath)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Stops the game if the transcript could not be written
+func checkRecording(err error) {
+	if err != nil {
+		log.Fatalf("Error: Could not record transcript: %v\n", err)
+	}
+}
+
+func play(args []string) {
+	flags := flag.NewFlagSet("play", flag.ExitOnError)
+	load := flags.String("load", "", "save file to resume from")
+	record := flags.String("record", "", "transcript file to record inputs and text to")
+	seed := seedFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tale play [--load file] [--record file] [--seed number] [directory or .tale file]...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *load != "" && *record != "" {
+		log.Fatal("Error: Cannot record a game loaded from a save, the transcript would not replay")
+	}
+
+	files := loader.Load(findTalePaths(flags.Args()))
+	if !reportErrors(files) {
+		os.Exit(1)
+	}
+
+	g := game.New(loader.Blocks(files), *seed)
+
+	var recorder *transcript.Recorder
+	if *record != "" {
+		f, err := os.Create(*record)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		recorder = transcript.NewRecorder(f)
+	}
+
+	if *load != "" {
+		if err := loadGame(g, *load); err != nil {
+			log.Fatalf("Error: %v\n", err)
+		}
+		fmt.Printf("(loaded %s, seed %d)\n\n", *load, g.Seed())
+	} else {
+		fmt.Printf("(seed %d)\n\n", g.Seed())
+
+		text := g.Start()
+		if text != "" {
+			fmt.Printf("%s\n\n", text)
+		}
+		if recorder != nil {
+			checkRecording(recorder.Start(g.Seed(), text))
+		}
+	}
+	reportRuntimeErrors(g)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Print("> ")
+
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+
+		if input != "" && !runMetaCommand(g, recorder, input) {
+			text, ok := g.Input(input)
+			if !ok {
+				text = transcript.NOTHING_HAPPENS
+			}
+
+			if text != "" {
+				fmt.Printf("%s\n\n", text)
+			}
+			if recorder != nil {
+				checkRecording(recorder.Input(input, text))
+			}
+			reportRuntimeErrors(g)
+		}
+
+		fmt.Print("> ")
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type jsonValue struct {
+	Type string `json:"type"`
+	Value any `json:"value,omitempty"`
+}
+
+type jsonState struct {
+	Variables map[string]Value `json:"variables"`
+	Objects map[string]map[string]Value `json:"objects"`
+}
+
+func parseValueType(name string) (ValueType, bool) {
+	for vt := UNSET; vt <= OBJECT; vt++ {
+		if vt.String() == name {
+			return vt, true
+		}
+	}
+	return UNSET, false
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	jv := jsonValue{Type: v.Type.String()}
+
+	switch v.Type {
+	case FLAG:
+		jv.Value = v.Flag
+	case NUMBER:
+		jv.Value = v.Number
+	case TEXT:
+		jv.Value = v.Text
+	case OBJECT:
+		jv.Value = v.Object
+	}
+
+	return json.Marshal(jv)
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var jv struct {
+		Type string `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &jv); err != nil {
+		return err
+	}
+
+	vt, ok := parseValueType(jv.Type)
+	if !ok {
+		return fmt.Errorf("unknown value type %q", jv.Type)
+	}
+
+	*v = Default(vt)
+	if len(jv.Value) == 0 {
+		return nil
+	}
+
+	var err error
+	switch vt {
+	case FLAG:
+		err = json.Unmarshal(jv.Value, &v.Flag)
+	case NUMBER:
+		err = json.Unmarshal(jv.Value, &v.Number)
+	case TEXT:
+		err = json.Unmarshal(jv.Value, &v.Text)
+	case OBJECT:
+		err = json.Unmarshal(jv.Value, &v.Object)
+		v.Object = normalize(v.Object)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %w", vt, err)
+	}
+	return nil
+}
+
+func (s *State) MarshalJSON() ([]byte, error) {
+	js := jsonState{
+		Variables: s.variables,
+		Objects: make(map[string]map[string]Value),
+	}
+
+	for name, obj := range s.objects {
+		js.Objects[name] = obj.Values
+	}
+
+	return json.Marshal(js)
+}
+
+// Replaces the state with a saved one, enforcing the same rules as Set
+// and SetValue
+func (s *State) UnmarshalJSON(data []byte) error {
+	var js jsonState
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+
+	loaded := New()
+
+	for name, values := range js.Objects {
+		loaded.AddObject(name)
+
+		for key, value := range values {
+			if err := loaded.SetValue(name, key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, value := range js.Variables {
+		if err := loaded.Set(name, value); err != nil {
+			return err
+		}
+	}
+
+	*s = *loaded
+	return nil
+}
@@ -0,0 +1,38 @@
+package state
+
+import (
+	"fmt"
+)
+
+// Whether an object is inside a container, either directly or inside other
+// objects within it
+func (s *State) isInside(object string, container string) bool {
+	object, container = normalize(object), normalize(container)
+
+	// Cycles are rejected when placing objects, but the number of steps is
+	// still limited in case one slips through
+	location := s.Location(object)
+	for range len(s.objects) {
+		if location == "" {
+			return false
+		}
+		if location == container {
+			return true
+		}
+		location = s.Location(location)
+	}
+
+	return false
+}
+
+// Objects may not end up inside themselves, directly or indirectly
+func (s *State) checkPlacement(object string, location string) error {
+	switch {
+	case location == object:
+		return fmt.Errorf("cannot place %s inside itself", object)
+	case location != "" && s.isInside(location, object):
+		return fmt.Errorf("cannot place %s in %s, %s is already inside %s", object, location, location, object)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Repeating decimals, like the result of 1 / 3, are rounded to this many
+// decimal places when displayed
+const DISPLAY_PRECISION = 10
+
+var ErrDivideByZero = errors.New("cannot divide by zero")
+
+// An exact number. Whole numbers and decimals are kept exactly as written,
+// so 0.1 + 0.2 is 0.3, and division keeps any remainder as a fraction
+// rather than rounding. The zero value is 0.
+type Num struct {
+	rat *big.Rat
+}
+
+func newNum(rat *big.Rat) Num {
+	return Num{rat: rat}
+}
+
+func (n Num) get() *big.Rat {
+	if n.rat == nil {
+		return new(big.Rat)
+	}
+	return n.rat
+}
+
+func Whole(n int64) Num {
+	return newNum(big.NewRat(n, 1))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Parses a number as written in a tale, with optional commas or underscores
+// as thousands separators and an optional decimal point
+func ParseNum(literal string) (Num, error) {
+	digits := strings.NewReplacer(",", "", "_", "").Replace(literal)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	if whole + fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Num{}, fmt.Errorf("%q is not a valid number", literal)
+	}
+
+	rat, ok := new(big.Rat).SetString("0" + whole + "." + fraction + "0")
+	if !ok {
+		return Num{}, fmt.Errorf("%q is not a valid number", literal)
+	}
+	return newNum(rat), nil
+}
+
+func (n Num) IsWhole() bool {
+	return n.get().IsInt()
+}
+
+func (n Num) IsZero() bool {
+	return n.get().Sign() == 0
+}
+
+func (n Num) Cmp(other Num) int {
+	return n.get().Cmp(other.get())
+}
+
+func (n Num) Neg() Num {
+	return newNum(new(big.Rat).Neg(n.get()))
+}
+
+func (n Num) Add(other Num) Num {
+	return newNum(new(big.Rat).Add(n.get(), other.get()))
+}
+
+func (n Num) Sub(other Num) Num {
+	return newNum(new(big.Rat).Sub(n.get(), other.get()))
+}
+
+func (n Num) Mul(other Num) Num {
+	return newNum(new(big.Rat).Mul(n.get(), other.get()))
+}
+
+func (n Num) Div(other Num) (Num, error) {
+	if other.IsZero() {
+		return Num{}, ErrDivideByZero
+	}
+	return newNum(new(big.Rat).Quo(n.get(), other.get())), nil
+}
+
+// The remainder after dividing by a whole number of times, which has the
+// same sign as n, so -7 % 2 is -1 and 7.5 % 2 is 1.5
+func (n Num) Rem(other Num) (Num, error) {
+	quotient, err := n.Div(other)
+	if err != nil {
+		return Num{}, err
+	}
+
+	q := quotient.get()
+	times := new(big.Int).Quo(q.Num(), q.Denom())
+	return n.Sub(other.Mul(newNum(new(big.Rat).SetInt(times)))), nil
+}
+
+// An exact decimal, or false if the number repeats forever
+func (n Num) decimal() (string, bool) {
+	rat := n.get()
+	if rat.IsInt() {
+		return rat.RatString(), true
+	}
+
+	prec, exact := rat.FloatPrec()
+	if !exact {
+		return "", false
+	}
+	return rat.FloatString(prec), true
+}
+
+func (n Num) String() string {
+	if s, ok := n.decimal(); ok {
+		return s
+	}
+
+	s := n.get().FloatString(DISPLAY_PRECISION)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Exact decimals are written as JSON numbers, and repeating decimals as
+// fractions in a string, like "1/3", so nothing is lost
+func (n Num) MarshalJSON() ([]byte, error) {
+	if s, ok := n.decimal(); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(n.get().RatString())
+}
+
+func (n *Num) UnmarshalJSON(data []byte) error {
+	text := string(data)
+
+	if strings.HasPrefix(text, "\"") {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return fmt.Errorf("%s is not a valid number", data)
+	}
+
+	*n = newNum(rat)
+	return nil
+}
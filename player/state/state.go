@@ -0,0 +1,205 @@
+package state
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"tale/blocks"
+)
+
+// Names with special meaning to every game
+const (
+	PLAYER = blocks.PLAYER
+	TALE = "tale"
+	LOCATION = "location"
+	NAME = "name"
+	REPEAT = "repeat"
+	REPEATS = "repeats"
+)
+
+// The names set by the game whenever a block repeats
+func IsRepeat(name string) bool {
+	return name == REPEAT || name == REPEATS
+}
+
+type Object struct {
+	Values map[string]Value
+}
+
+// Variables and objects in a running game. All names are case insensitive.
+type State struct {
+	variables map[string]Value
+	objects map[string]*Object
+}
+
+func normalize(name string) string {
+	return strings.ToLower(name)
+}
+
+func New() *State {
+	s := &State{
+		variables: make(map[string]Value),
+		objects: make(map[string]*Object),
+	}
+
+	s.AddObject(PLAYER)
+	s.AddObject(TALE)
+	return s
+}
+
+func checkType(name string, prev Value, next Value) error {
+	if prev.Type != UNSET && next.Type != UNSET && prev.Type != next.Type {
+		return fmt.Errorf("cannot set %s to a %s, it is a %s", name, next.Type, prev.Type)
+	}
+	return nil
+}
+
+func (s *State) IsObject(name string) bool {
+	_, ok := s.objects[normalize(name)]
+	return ok
+}
+
+// Adds an object with no values if it does not already exist
+func (s *State) AddObject(name string) {
+	name = normalize(name)
+
+	if _, ok := s.objects[name]; !ok {
+		s.objects[name] = &Object{Values: make(map[string]Value)}
+	}
+}
+
+func (s *State) Objects() []string {
+	var names []string
+	for name := range s.objects {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+func (s *State) Variables() []string {
+	var names []string
+	for name := range s.variables {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// Objects referenced by name evaluate to themselves
+func (s *State) Get(name string) Value {
+	name = normalize(name)
+
+	if _, ok := s.objects[name]; ok {
+		return ObjectRef(name)
+	}
+	return s.variables[name]
+}
+
+func (s *State) Set(name string, value Value) error {
+	name = normalize(name)
+
+	if _, ok := s.objects[name]; ok {
+		return fmt.Errorf("cannot set %s, it is an object", name)
+	}
+
+	if err := checkType(name, s.variables[name], value); err != nil {
+		return err
+	}
+
+	s.variables[name] = value
+	return nil
+}
+
+// Reverts a variable to the default value of its type
+func (s *State) Unset(name string) {
+	name = normalize(name)
+	s.variables[name] = Default(s.variables[name].Type)
+}
+
+func (s *State) GetValue(object string, key string) Value {
+	if obj, ok := s.objects[normalize(object)]; ok {
+		return obj.Values[normalize(key)]
+	}
+	return Value{}
+}
+
+// Sets a value of an object, adding the object if needed
+func (s *State) SetValue(object string, key string, value Value) error {
+	object, key = normalize(object), normalize(key)
+	s.AddObject(object)
+	obj := s.objects[object]
+
+	switch key {
+	case LOCATION:
+		if value.Type != OBJECT {
+			return fmt.Errorf("cannot set %s of %s to a %s, it must be an object", key, object, value.Type)
+		}
+		if err := s.checkPlacement(object, value.Object); err != nil {
+			return err
+		}
+		if value.Object != "" {
+			s.AddObject(value.Object)
+		}
+	case NAME:
+		if value.Type != TEXT {
+			return fmt.Errorf("cannot set %s of %s to a %s, it must be text", key, object, value.Type)
+		}
+	}
+
+	if err := checkType(key + " of " + object, obj.Values[key], value); err != nil {
+		return err
+	}
+
+	obj.Values[key] = value
+	return nil
+}
+
+// Location and name always have a type, even before they are set
+func (s *State) UnsetValue(object string, key string) {
+	object, key = normalize(object), normalize(key)
+	obj, ok := s.objects[object]
+	if !ok {
+		return
+	}
+
+	switch key {
+	case LOCATION:
+		obj.Values[key] = Default(OBJECT)
+	case NAME:
+		obj.Values[key] = Default(TEXT)
+	default:
+		obj.Values[key] = Default(obj.Values[key].Type)
+	}
+}
+
+// The object containing an object, or "" if it has not been placed
+func (s *State) Location(object string) string {
+	return s.GetValue(object, LOCATION).Object
+}
+
+func (s *State) Place(object string, location string) error {
+	return s.SetValue(object, LOCATION, ObjectRef(location))
+}
+
+// The display name of an object, defaulting to its identifier
+func (s *State) Name(object string) string {
+	if name := s.GetValue(object, NAME).Text; name != "" {
+		return name
+	}
+	return normalize(object)
+}
+
+func (s *State) SetName(object string, name string) error {
+	return s.SetValue(object, NAME, Text(name))
+}
+
+// Displays a value as text, using the name of objects
+func (s *State) Display(value Value) string {
+	if value.Type == OBJECT {
+		return s.Name(value.Object)
+	}
+	return value.String()
+}
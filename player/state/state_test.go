@@ -0,0 +1,249 @@
+package state
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func num(literal string) Num {
+	n, err := ParseNum(literal)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func expectValue(t *testing.T, actual Value, expected Value) {
+	if actual.Type != expected.Type || !actual.Equals(expected) {
+		t.Fatalf("expected=%#v, got=%#v", expected, actual)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	s := New()
+
+	expectValue(t, s.Get("score"), Value{})
+
+	if err := s.Set("Score", Number(Whole(3))); err != nil {
+		t.Fatal(err)
+	}
+	expectValue(t, s.Get("SCORE"), Number(Whole(3)))
+
+	if err := s.Set("score", Text("three")); err == nil {
+		t.Fatalf("expected an error changing a number to text")
+	}
+	expectValue(t, s.Get("score"), Number(Whole(3)))
+
+	s.Unset("score")
+	expectValue(t, s.Get("score"), Number(Whole(0)))
+
+	s.Set("lit", Flag(true))
+	s.Set("message", Text("Hello"))
+
+	vars := s.Variables()
+	if len(vars) != 3 || vars[0] != "lit" || vars[1] != "message" || vars[2] != "score" {
+		t.Fatalf("unexpected variables %v", vars)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	s := New()
+
+	if !s.IsObject("Player") || !s.IsObject("tale") {
+		t.Fatalf("expected built-in player and tale objects")
+	}
+
+	s.AddObject("Door")
+	expectValue(t, s.Get("door"), ObjectRef("door"))
+
+	if err := s.Set("door", Flag(true)); err == nil {
+		t.Fatalf("expected an error setting an object")
+	}
+
+	s.SetValue("door", "Locked", Flag(true))
+	expectValue(t, s.GetValue("DOOR", "locked"), Flag(true))
+
+	s.UnsetValue("door", "locked")
+	expectValue(t, s.GetValue("door", "locked"), Flag(false))
+
+	if err := s.SetValue("door", "locked", Number(Whole(1))); err == nil {
+		t.Fatalf("expected an error changing a flag to a number")
+	}
+
+	objects := s.Objects()
+	if len(objects) != 3 || objects[0] != "door" || objects[1] != "player" || objects[2] != "tale" {
+		t.Fatalf("unexpected objects %v", objects)
+	}
+}
+
+func TestLocationAndName(t *testing.T) {
+	s := New()
+
+	if s.Name("player") != "player" {
+		t.Fatalf("expected default name of player, got %q", s.Name("player"))
+	}
+
+	s.SetName("Player", "Alice")
+	if s.Name("player") != "Alice" || s.Display(s.Get("PLAYER")) != "Alice" {
+		t.Fatalf("expected name Alice, got %q", s.Name("player"))
+	}
+
+	if err := s.Place("player", "Cell"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Location("player") != "cell" || !s.IsObject("cell") {
+		t.Fatalf("expected player in cell, got %q", s.Location("player"))
+	}
+
+	if err := s.SetValue("player", LOCATION, Text("cell")); err == nil {
+		t.Fatalf("expected an error setting location to text")
+	}
+
+	if err := s.SetValue("player", NAME, Number(Whole(1))); err == nil {
+		t.Fatalf("expected an error setting name to a number")
+	}
+}
+
+func TestValues(t *testing.T) {
+	if !(Value{}).Equals(Number(Whole(0))) || !Flag(false).Equals(Value{}) || Text("a").Equals(Text("b")) {
+		t.Fatalf("unexpected equality")
+	}
+
+	if Number(num("1.5")).String() != "1.5" || Flag(true).String() != "yes" || (Value{}).IsSet() {
+		t.Fatalf("unexpected display")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	s := New()
+	s.Set("score", Number(num("2.5")))
+	s.Set("lit", Flag(true))
+	s.Set("message", Text("Hello"))
+	s.Set("count", Number(Whole(3)))
+	s.Unset("count")
+	s.Place("lamp", "cellar")
+	s.SetName("lamp", "Brass Lamp")
+	s.SetValue("lamp", "lit", Flag(false))
+	s.AddObject("key")
+	s.UnsetValue("key", LOCATION)
+	s.UnsetValue("key", NAME)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	expectValue(t, loaded.Get("score"), Number(num("2.5")))
+	expectValue(t, loaded.Get("lit"), Flag(true))
+	expectValue(t, loaded.Get("message"), Text("Hello"))
+	expectValue(t, loaded.Get("count"), Number(Whole(0)))
+	expectValue(t, loaded.GetValue("lamp", "lit"), Flag(false))
+
+	if loaded.Location("lamp") != "cellar" || loaded.Name("lamp") != "Brass Lamp" || !loaded.IsObject("cellar") {
+		t.Fatalf("unexpected lamp %v", loaded.objects["lamp"])
+	}
+	if loaded.Location("key") != "" || loaded.Name("key") != "key" {
+		t.Fatalf("unexpected key %v", loaded.objects["key"])
+	}
+
+	invalid := []string{
+		`{"variables": {"score": {"type": "percent", "value": 3}}}`,
+		`{"variables": {"score": {"type": "number", "value": "three"}}}`,
+		`{"variables": {"player": {"type": "flag", "value": true}}}`,
+		`{"objects": {"lamp": {"location": {"type": "text", "value": "cellar"}}}}`,
+	}
+	for _, data := range invalid {
+		if err := json.Unmarshal([]byte(data), New()); err == nil {
+			t.Fatalf("expected an error loading %s", data)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		actual Num
+		expected string
+	}{
+		{num("1,000,000"), "1000000"},
+		{num("1_000_000_000"), "1000000000"},
+		{num(".5"), "0.5"},
+		{num("5."), "5"},
+		{num("0.1").Add(num("0.2")), "0.3"},
+		{Whole(7).Sub(num("10.25")), "-3.25"},
+		{num("1.5").Mul(Whole(4)), "6"},
+		{Whole(2).Neg(), "-2"},
+	}
+
+	for _, tt := range tests {
+		if tt.actual.String() != tt.expected {
+			t.Fatalf("expected=%s, got=%s", tt.expected, tt.actual)
+		}
+	}
+
+	third, _ := Whole(1).Div(Whole(3))
+	if third.String() != "0.3333333333" || third.IsWhole() || !third.Mul(Whole(3)).IsWhole() {
+		t.Fatalf("unexpected third %s", third)
+	}
+
+	remainders := []struct {
+		left Num
+		right Num
+		expected string
+	}{
+		{Whole(7), Whole(2), "1"},
+		{Whole(-7), Whole(2), "-1"},
+		{num("7.5"), Whole(2), "1.5"},
+	}
+	for _, tt := range remainders {
+		rem, err := tt.left.Rem(tt.right)
+		if err != nil || rem.String() != tt.expected {
+			t.Fatalf("%s %% %s: expected=%s, got=%s (%v)", tt.left, tt.right, tt.expected, rem, err)
+		}
+	}
+
+	if _, err := Whole(1).Div(Num{}); err != ErrDivideByZero {
+		t.Fatalf("expected divide by zero, got %v", err)
+	}
+	if _, err := Whole(1).Rem(Whole(0)); err != ErrDivideByZero {
+		t.Fatalf("expected divide by zero, got %v", err)
+	}
+
+	for _, literal := range []string{".", "1.2.3", "1e5", ""} {
+		if _, err := ParseNum(literal); err == nil {
+			t.Fatalf("expected %q to be invalid", literal)
+		}
+	}
+
+	data, err := json.Marshal([]Num{num("2.5"), third})
+	if err != nil || string(data) != `[2.5,"1/3"]` {
+		t.Fatalf("unexpected json %s (%v)", data, err)
+	}
+
+	var loaded []Num
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded[0].Cmp(num("2.5")) != 0 || loaded[1].Cmp(third) != 0 {
+		t.Fatalf("unexpected numbers %v (%v)", loaded, err)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	s := New()
+	s.Place("chest", "cell")
+	s.Place("key", "chest")
+	s.Place("cell", "dungeon")
+
+	if err := s.Place("chest", "chest"); err == nil || err.Error() != "cannot place chest inside itself" {
+		t.Fatalf("expected an error placing chest inside itself, got %v", err)
+	}
+	if err := s.Place("dungeon", "key"); err == nil || err.Error() != "cannot place dungeon in key, key is already inside dungeon" {
+		t.Fatalf("expected an error placing dungeon in key, got %v", err)
+	}
+	if s.Location("dungeon") != "" {
+		t.Fatalf("expected dungeon to stay where it was")
+	}
+}
@@ -0,0 +1,107 @@
+package state
+
+type ValueType uint8
+
+const (
+	UNSET ValueType = iota
+	FLAG
+	NUMBER
+	TEXT
+	OBJECT
+)
+
+func (vt ValueType) String() string {
+	switch vt {
+	case UNSET: return "unset"
+	case FLAG: return "flag"
+	case NUMBER: return "number"
+	case TEXT: return "text"
+	case OBJECT: return "object"
+	default: return "invalid value type"
+	}
+}
+
+// Only the field matching the Type is used. An UNSET value has no type yet
+// and behaves like the default value of whatever type it is used as.
+type Value struct {
+	Type ValueType
+	Flag bool
+	Number Num
+	Text string
+	Object string
+}
+
+func Flag(flag bool) Value {
+	return Value{Type: FLAG, Flag: flag}
+}
+
+// Whether a flag as written in a tale, like "yes" or "off", is set
+func ParseFlag(literal string) bool {
+	return literal == "yes" || literal == "on" || literal == "true"
+}
+
+func Number(number Num) Value {
+	return Value{Type: NUMBER, Number: number}
+}
+
+func Text(text string) Value {
+	return Value{Type: TEXT, Text: text}
+}
+
+func ObjectRef(name string) Value {
+	return Value{Type: OBJECT, Object: normalize(name)}
+}
+
+// The default value for a type, e.g. 0 for numbers
+func Default(vt ValueType) Value {
+	return Value{Type: vt}
+}
+
+// Whether a value is set, as checked by a condition like "= unlocked ="
+func (v Value) IsSet() bool {
+	switch v.Type {
+	case FLAG:
+		return v.Flag
+	case NUMBER:
+		return !v.Number.IsZero()
+	case TEXT:
+		return v.Text != ""
+	case OBJECT:
+		return v.Object != ""
+	default:
+		return false
+	}
+}
+
+func (v Value) Equals(other Value) bool {
+	if v.Type == UNSET {
+		v = Default(other.Type)
+	}
+	if other.Type == UNSET {
+		other = Default(v.Type)
+	}
+
+	if v.Type == NUMBER && other.Type == NUMBER {
+		return v.Number.Cmp(other.Number) == 0
+	}
+	return v == other
+}
+
+// Objects are displayed by the State using their name value
+func (v Value) String() string {
+	switch v.Type {
+	case FLAG:
+		if v.Flag {
+			return "yes"
+		}
+		return "no"
+	case NUMBER:
+		return v.Number.String()
+	case TEXT:
+		return v.Text
+	case OBJECT:
+		return v.Object
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"tale/loader"
+	"tale/transcript"
+)
+
+// Splits transcript arguments from tale arguments. Without any transcript
+// arguments, every transcript within the tale's directories is used.
+func findTranscriptPaths(pwd string, args []string) ([]string, []string) {
+	var transcriptPaths, taleArgs, dirPaths []string
+
+	for _, arg := range args {
+		absPath := arg
+		if !path.IsAbs(absPath) {
+			absPath = path.Join(pwd, absPath)
+		}
+
+		switch path.Ext(absPath) {
+		case transcript.EXTENSION:
+			transcriptPaths = append(transcriptPaths, absPath)
+		case "":
+			dirPaths = append(dirPaths, absPath)
+			taleArgs = append(taleArgs, arg)
+		default:
+			taleArgs = append(taleArgs, arg)
+		}
+	}
+
+	if len(transcriptPaths) > 0 {
+		return transcriptPaths, taleArgs
+	}
+
+	if len(taleArgs) == 0 {
+		dirPaths = append(dirPaths, pwd)
+	}
+	for _, dirPath := range dirPaths {
+		transcriptPaths = append(transcriptPaths, loader.FindNested(dirPath, transcript.EXTENSION)...)
+	}
+
+	return transcriptPaths, taleArgs
+}
+
+func testTale(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	seed := seedFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tale test [--seed number] [directory, .tale file, or .transcript file]...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	transcriptPaths, taleArgs := findTranscriptPaths(pwd, flags.Args())
+	if len(transcriptPaths) == 0 {
+		log.Fatal("Error: No .transcript files found!")
+	}
+
+	files := loader.Load(findTalePaths(taleArgs))
+	if !reportErrors(files) {
+		os.Exit(1)
+	}
+	taleBlocks := loader.Blocks(files)
+
+	failed := 0
+	for _, transcriptPath := range transcriptPaths {
+		t, diags := transcript.Load(transcriptPath)
+		if len(diags) == 0 {
+			diags = transcript.Run(taleBlocks, t, *seed)
+		}
+
+		for _, diag := range diags {
+			printDiagnostic(pwd, diag)
+		}
+
+		relPath, err := filepath.Rel(pwd, transcriptPath)
+		if err != nil {
+			relPath = transcriptPath
+		}
+
+		// Transcripts without their own seed can be replayed with --seed
+		switch {
+		case len(diags) == 0:
+			fmt.Printf("PASS %s\n", relPath)
+		case t.HasSeed:
+			fmt.Printf("FAIL %s\n", relPath)
+			failed++
+		default:
+			fmt.Printf("FAIL %s (seed %d)\n", relPath, *seed)
+			failed++
+		}
+	}
+
+	fmt.Printf("%d transcripts played, %d failed\n", len(transcriptPaths), failed)
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
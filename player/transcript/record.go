@@ -0,0 +1,64 @@
+package transcript
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Lines which Parse would read as something other than text
+func escapeLine(line string) string {
+	if line != "" && strings.ContainsRune(">=#@\\", rune(line[0])) {
+		return "\\" + line
+	}
+	return line
+}
+
+// Writes a transcript as a game is played, in the format Parse reads, so a
+// playthrough can be replayed as a test
+type Recorder struct {
+	w io.Writer
+	empty bool
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, empty: true}
+}
+
+func (r *Recorder) writeStep(step Step) error {
+	var lines []string
+
+	if step.Input != "" {
+		if !r.empty {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "> " + step.Input)
+	}
+
+	for _, line := range step.Output {
+		lines = append(lines, escapeLine(line))
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	r.empty = false
+	_, err := fmt.Fprintln(r.w, strings.Join(lines, "\n"))
+	return err
+}
+
+// Records the seed of the game and the text displayed when it starts
+func (r *Recorder) Start(seed uint64, text string) error {
+	if _, err := fmt.Fprintf(r.w, "@%s %d\n", SEED_SETTING, seed); err != nil {
+		return err
+	}
+
+	r.empty = false
+	return r.writeStep(Step{Output: splitOutput(text)})
+}
+
+// Records an input and the text it displayed, or NOTHING_HAPPENS
+func (r *Recorder) Input(input string, text string) error {
+	return r.writeStep(Step{Input: input, Output: splitOutput(text)})
+}
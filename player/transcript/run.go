@@ -0,0 +1,125 @@
+package transcript
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/game"
+)
+
+// What play displays when no block matches an input
+const NOTHING_HAPPENS = "(nothing happens)"
+
+func splitOutput(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return trimLines(lines)
+}
+
+// Lists the lines of two outputs, marking those only expected with "-"
+// and those only displayed with "+"
+func diff(expected []string, actual []string) string {
+	// Longest common subsequence, so matching lines line up
+	lengths := make([][]int, len(expected) + 1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(actual) + 1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				lengths[i][j] = lengths[i + 1][j + 1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i + 1][j], lengths[i][j + 1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			fmt.Fprintf(&out, "\n  %s", expected[i])
+			i++
+			j++
+		case j == len(actual) || (i < len(expected) && lengths[i + 1][j] >= lengths[i][j + 1]):
+			fmt.Fprintf(&out, "\n- %s", expected[i])
+			i++
+		default:
+			fmt.Fprintf(&out, "\n+ %s", actual[j])
+			j++
+		}
+	}
+
+	return out.String()
+}
+
+type runner struct {
+	transcript Transcript
+	game *game.Game
+	failures []diagnostics.Diagnostic
+}
+
+func (r *runner) failf(line int, format string, args ...any) {
+	r.failures = append(r.failures, diagnostics.Diagnostic{
+		Path: r.transcript.Path,
+		Line: line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *runner) runStep(step Step) {
+	var text string
+
+	if step.Input == "" {
+		text = r.game.Start()
+	} else if output, ok := r.game.Input(step.Input); ok {
+		text = output
+	} else {
+		text = NOTHING_HAPPENS
+	}
+
+	for _, err := range r.game.Errors() {
+		if err.IsError() {
+			r.failf(step.Line, "runtime error: %s", err)
+		}
+	}
+
+	actual := splitOutput(text)
+	if !slices.Equal(step.Output, actual) {
+		if step.Input == "" {
+			r.failf(step.Line, "start text does not match:%s", diff(step.Output, actual))
+		} else {
+			r.failf(step.Line, "text for %q does not match:%s", step.Input, diff(step.Output, actual))
+		}
+	}
+
+	for _, check := range step.Checks {
+		if !r.game.Test(check.Condition) {
+			r.failf(check.Line, "%q is not true", check.Source)
+		}
+		for _, err := range r.game.Errors() {
+			r.failf(check.Line, "%s", err.Message)
+		}
+	}
+}
+
+// Plays a transcript against a new game of a tale, returning every step
+// which did not go as expected. The game uses the transcript's own seed if
+// it has one, otherwise the seed given.
+func Run(taleBlocks []blocks.Block, t Transcript, seed uint64) []diagnostics.Diagnostic {
+	if t.HasSeed {
+		seed = t.Seed
+	}
+	r := &runner{transcript: t, game: game.New(taleBlocks, seed)}
+
+	for _, step := range t.Steps {
+		r.runStep(step)
+	}
+
+	return r.failures
+}
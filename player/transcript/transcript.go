@@ -0,0 +1,140 @@
+// Reads and plays transcripts, which script the inputs of a playthrough
+// along with the text each should display. See docs/transcript-format.md.
+package transcript
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"tale/blocks"
+	"tale/diagnostics"
+	"tale/parser"
+)
+
+const (
+	EXTENSION = ".transcript"
+	SEED_SETTING = "seed"
+)
+
+// A condition to test against the state of the game after a step
+type Check struct {
+	Line int
+	Source string
+	Condition blocks.Expression
+}
+
+// One input and what it should do. The first step has no input and holds
+// the text displayed when the game starts.
+type Step struct {
+	Line int
+	Input string
+	Output []string
+	Checks []Check
+}
+
+// HasSeed is set when the transcript sets its own seed with "@seed", which
+// then takes the place of any other seed it is played with
+type Transcript struct {
+	Path string
+	Seed uint64
+	HasSeed bool
+	Steps []Step
+}
+
+// Removes the leading and trailing empty lines of an output
+func trimLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines) - 1]) == "" {
+		lines = lines[:len(lines) - 1]
+	}
+	return lines
+}
+
+func Parse(path string, input string) (Transcript, []diagnostics.Diagnostic) {
+	t := Transcript{Path: path, Steps: []Step{{Line: 1}}}
+	var diags []diagnostics.Diagnostic
+
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+
+	for i, line := range strings.Split(input, "\n") {
+		lineNum := i + 1
+		step := &t.Steps[len(t.Steps) - 1]
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			// Comments are ignored
+
+		case strings.HasPrefix(line, "@"):
+			fields := strings.Fields(strings.TrimPrefix(line, "@"))
+			message := ""
+
+			switch {
+			case len(t.Steps) > 1:
+				message = "settings must come before the first input"
+			case len(fields) != 2 || fields[0] != SEED_SETTING:
+				message = fmt.Sprintf("unknown setting %q, the only setting is \"@seed\" followed by a whole number", strings.TrimSpace(line))
+			default:
+				seed, err := strconv.ParseUint(fields[1], 10, 64)
+				if err != nil {
+					message = fmt.Sprintf("%q is not a valid seed, it must be a whole number", fields[1])
+				}
+				t.Seed, t.HasSeed = seed, err == nil
+			}
+
+			if message != "" {
+				diags = append(diags, diagnostics.Diagnostic{Path: path, Line: lineNum, Message: message})
+			}
+
+		case strings.HasPrefix(line, ">"):
+			input := strings.TrimSpace(strings.TrimPrefix(line, ">"))
+			if input == "" {
+				diags = append(diags, diagnostics.Diagnostic{Path: path, Line: lineNum, Message: "> must be followed by a player input"})
+			}
+			t.Steps = append(t.Steps, Step{Line: lineNum, Input: input})
+
+		case strings.HasPrefix(line, "="):
+			source := strings.TrimSpace(strings.TrimPrefix(line, "="))
+			condition, errs := parser.ParseCondition(path, source)
+
+			for _, err := range errs {
+				err.Line = lineNum
+				err.Column += strings.Index(line, source)
+				diags = append(diags, err)
+			}
+			if len(errs) == 0 {
+				step.Checks = append(step.Checks, Check{Line: lineNum, Source: source, Condition: condition})
+			}
+
+		default:
+			line = strings.TrimRight(line, " \t")
+			step.Output = append(step.Output, strings.TrimPrefix(line, "\\"))
+		}
+	}
+
+	for i := range t.Steps {
+		t.Steps[i].Output = trimLines(t.Steps[i].Output)
+	}
+
+	return t, diags
+}
+
+func Load(path string) (Transcript, []diagnostics.Diagnostic) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		message := err.Error()
+
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			message = pathErr.Err.Error()
+		}
+
+		return Transcript{Path: path}, []diagnostics.Diagnostic{{Path: path, Message: message}}
+	}
+
+	return Parse(path, string(data))
+}
@@ -0,0 +1,154 @@
+package transcript
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"slices"
+	"tale/check"
+	"tale/game"
+	"tale/loader"
+	"tale/parser"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := "# A comment\n@seed 42\nWelcome!\n\n> greet  \nHello\n\n\\> Not an input\n= greeted and score is 1\n\n> wave\n(nothing happens)\n= score +\n@seed 7"
+
+	tr, diags := Parse("test.transcript", input)
+	if len(diags) != 2 ||
+		diags[0].Error() != `test.transcript:13:9: error: expected a value after "+"` ||
+		diags[1].Error() != "test.transcript:14: error: settings must come before the first input" {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+
+	if !tr.HasSeed || tr.Seed != 42 {
+		t.Fatalf("expected seed 42, got %d", tr.Seed)
+	}
+
+	if len(tr.Steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d: %v", len(tr.Steps), tr.Steps)
+	}
+
+	start, greet, wave := tr.Steps[0], tr.Steps[1], tr.Steps[2]
+	if start.Input != "" || !slices.Equal(start.Output, []string{"Welcome!"}) {
+		t.Fatalf("unexpected start step %+v", start)
+	}
+	if greet.Line != 5 || greet.Input != "greet" || !slices.Equal(greet.Output, []string{"Hello", "", "> Not an input"}) {
+		t.Fatalf("unexpected greet step %+v", greet)
+	}
+	if len(greet.Checks) != 1 || greet.Checks[0].Line != 9 || greet.Checks[0].Condition.String() != "(and greeted (is score 1))" {
+		t.Fatalf("unexpected greet checks %+v", greet.Checks)
+	}
+	if wave.Input != "wave" || !slices.Equal(wave.Output, []string{NOTHING_HAPPENS}) || len(wave.Checks) != 0 {
+		t.Fatalf("unexpected wave step %+v", wave)
+	}
+}
+
+func TestRun(t *testing.T) {
+	taleBlocks := parser.MustParse(t, path.Join(t.TempDir(), "start.tale"), "Welcome!\n\n> greet >\n{set greeted}Hello\n\nHow are you?\n\n> count >\n{set score score + 1}Counted {score}")
+
+	passing, _ := Parse("pass.transcript", "Welcome!\n\n> greet\nHello\n\nHow are you?\n= greeted\n\n> count\nCounted 1\n> count\nCounted 2\n= score is 2\n\n> wave\n(nothing happens)")
+	if failures := Run(taleBlocks, passing, 1); len(failures) > 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+
+	failing, _ := Parse("fail.transcript", "Welcome!\n\n> count\nCounted 2\n= score is 2\n= greeted")
+	failures := Run(taleBlocks, failing, 1)
+
+	expected := []string{
+		"fail.transcript:3: error: text for \"count\" does not match:\n- Counted 2\n+ Counted 1",
+		"fail.transcript:5: error: \"score is 2\" is not true",
+		"fail.transcript:6: error: \"greeted\" is not true",
+	}
+	if len(failures) != len(expected) {
+		t.Fatalf("expected %d failures, got %d: %v", len(expected), len(failures), failures)
+	}
+	for i, failure := range failures {
+		if failure.Error() != expected[i] {
+			t.Fatalf("expected=%q, got=%q", expected[i], failure.Error())
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	taleBlocks := parser.MustParse(t, path.Join(t.TempDir(), "start.tale"), "> roll >\n{chance}{choice}1{/choice}{choice}2{/choice}{choice}3{/choice}{choice}4{/choice}{/chance}")
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	g := game.New(taleBlocks, 9)
+	recorder.Start(g.Seed(), g.Start())
+	for range 8 {
+		text, _ := g.Input("roll")
+		recorder.Input("roll", text)
+	}
+
+	tr, _ := Parse("seeded.transcript", buf.String())
+	for _, seed := range []uint64{1, 9, 12345} {
+		if failures := Run(taleBlocks, tr, seed); len(failures) > 0 {
+			t.Fatalf("unexpected failures with seed %d: %v", seed, failures)
+		}
+	}
+}
+
+// The fixture exported to Taelmoor must also check cleanly and play
+// without any errors
+func TestInitiationFixture(t *testing.T) {
+	root, err := filepath.Abs("../../tales/initiation-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := loader.Load(loader.FindNested(root, ".tale"))
+	if errors := loader.Errors(files); len(errors) > 0 {
+		t.Fatalf("unexpected parse errors %v", errors)
+	}
+
+	taleBlocks := loader.Blocks(files)
+	if diags := check.All(taleBlocks); len(diags) > 0 {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+
+	tr, diags := Load(filepath.Join(root, "playthrough.transcript"))
+	if len(diags) > 0 {
+		t.Fatalf("unexpected transcript errors %v", diags)
+	}
+	if failures := Run(taleBlocks, tr, 1); len(failures) > 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := diff([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	expected := "\n  a\n- b\n  c\n+ d"
+	if got != expected {
+		t.Fatalf("expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	taleBlocks := parser.MustParse(t, path.Join(t.TempDir(), "start.tale"), "Welcome!\n\n> greet >\nHello\n\n\\> Not an input\n\\= Nor a condition\n@ Nor a setting\n\n> count >\n{set score score + 1}")
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	recorder.Start(42, "Welcome!")
+	recorder.Input("greet", "Hello\n\n> Not an input\n= Nor a condition\n@ Nor a setting")
+	recorder.Input("count", "")
+	recorder.Input("wave", NOTHING_HAPPENS)
+
+	expected := "@seed 42\nWelcome!\n\n> greet\nHello\n\n\\> Not an input\n\\= Nor a condition\n\\@ Nor a setting\n\n> count\n\n> wave\n(nothing happens)\n"
+	if buf.String() != expected {
+		t.Fatalf("expected=%q, got=%q", expected, buf.String())
+	}
+
+	tr, diags := Parse("recorded.transcript", buf.String())
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	if !tr.HasSeed || tr.Seed != 42 {
+		t.Fatalf("expected the recorded seed 42, got %d", tr.Seed)
+	}
+	if failures := Run(taleBlocks, tr, 1); len(failures) > 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+}